@@ -2,6 +2,7 @@ package dictionary
 
 import (
 	"bufio"
+	"context"
 	"os"
 
 	pkgerr "github.com/pkg/errors"
@@ -17,7 +18,7 @@ func NewFileReader(fileName string) *FileReader {
 	}
 }
 
-func (fr *FileReader) Run(handler func(word string) error) error {
+func (fr *FileReader) Run(ctx context.Context, handler func(word string) error) error {
 	f, err := os.Open(fr.fileName)
 	if err != nil {
 		return pkgerr.Wrapf(err, "failed open file '%s'", fr.fileName)
@@ -27,6 +28,10 @@ func (fr *FileReader) Run(handler func(word string) error) error {
 
 	scanner := bufio.NewScanner(f)
 	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return pkgerr.Wrapf(err, "scaning file '%s' interrupted", fr.fileName)
+		}
+
 		if err := handler(scanner.Text()); err != nil {
 			return pkgerr.Wrapf(err, "scaning file '%s' aborted due to error", fr.fileName)
 		}
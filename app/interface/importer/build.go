@@ -0,0 +1,161 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+
+	"morphbits.io/app/usecase/keyboard"
+)
+
+var ErrNoKeys = errors.New("no character keys found")
+
+// Levels of the imported keys.
+const (
+	baseLevel = iota
+	shiftLevel
+	altGrLevel
+
+	levelsCount
+)
+
+// noChar marks the key without the character on the level, as in the layout definition.
+const noChar = ' '
+
+// keyCap is the imported key with the characters typed on each level.
+type keyCap struct {
+	position keyboard.Point
+	width    float64
+	levels   [levelsCount]rune // noSymbol if the level types nothing
+}
+
+// definitionBuilder collects the imported rows and the modifier and special key positions.
+type definitionBuilder struct {
+	name     string
+	metadata map[string]string
+	rows     [][]keyCap
+	shift    []keyboard.Point
+	altGr    []keyboard.Point
+	special  map[string]keyboard.Point
+}
+
+func newDefinitionBuilder(name string) *definitionBuilder {
+	return &definitionBuilder{
+		name:     name,
+		metadata: make(map[string]string),
+		rows:     nil,
+		shift:    nil,
+		altGr:    nil,
+		special:  make(map[string]keyboard.Point),
+	}
+}
+
+// build makes the definition. The character typed by several keys or levels is kept
+// on the first base level key only, the later duplicates are dropped.
+func (b *definitionBuilder) build() (*keyboard.Definition, error) {
+	placed := make(map[rune]bool)
+
+	placeable := func(char rune) bool {
+		if char == noSymbol || char == noChar || placed[char] {
+			return false
+		}
+
+		placed[char] = true
+
+		return true
+	}
+
+	// The base level first, so the duplicates on the upper levels never hide the base characters
+	hasBase := make([][]bool, len(b.rows))
+	for i, row := range b.rows {
+		hasBase[i] = make([]bool, len(row))
+		for j := range row {
+			hasBase[i][j] = placeable(row[j].levels[baseLevel])
+		}
+	}
+
+	var layers [levelsCount][][]rune
+
+	for level := shiftLevel; level < levelsCount; level++ {
+		layers[level] = make([][]rune, len(b.rows))
+
+		for i, row := range b.rows {
+			for j := range row {
+				char := row[j].levels[level]
+				if !hasBase[i][j] || !placeable(char) {
+					char = noChar
+				}
+
+				layers[level][i] = append(layers[level][i], char)
+			}
+		}
+	}
+
+	def := keyboard.NewDefinition(b.name, nil)
+	def.Metadata = b.metadata
+
+	for i, row := range b.rows {
+		var (
+			keys, shift, altGr []rune
+			coordinates        []keyboard.Point
+			widths             []float64
+		)
+
+		for j := range row {
+			if !hasBase[i][j] {
+				continue
+			}
+
+			keys = append(keys, row[j].levels[baseLevel])
+			shift = append(shift, layers[shiftLevel][i][j])
+			altGr = append(altGr, layers[altGrLevel][i][j])
+			coordinates = append(coordinates, row[j].position)
+			widths = append(widths, row[j].width)
+		}
+
+		if len(keys) == 0 {
+			continue
+		}
+
+		def.Rows = append(def.Rows, keyboard.Row{
+			Name:        fmt.Sprintf("row %d", i),
+			Keys:        string(keys),
+			Shift:       layer(shift, b.shift),
+			AltGr:       layer(altGr, b.altGr),
+			Fingers:     "",
+			Offset:      0,
+			Coordinates: coordinates,
+			Widths:      widths,
+		})
+	}
+
+	if len(def.Rows) == 0 {
+		return nil, ErrNoKeys
+	}
+
+	def.Modifiers = &keyboard.Modifiers{Shift: b.shift, AltGr: b.altGr}
+	if len(b.special) != 0 {
+		def = def.WithSpecial(b.special)
+	}
+
+	if err := def.Validate(); err != nil {
+		return nil, err
+	}
+
+	return def, nil
+}
+
+// layer returns the row characters on the level, empty if there is no modifier
+// for the level or no characters.
+func layer(chars []rune, modifiers []keyboard.Point) string {
+	if len(modifiers) == 0 {
+		return ""
+	}
+
+	for _, char := range chars {
+		if char != noChar {
+			return string(chars)
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,134 @@
+package importer
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// noSymbol is returned for the keysyms which type nothing by themselves, e.g. the dead keys.
+const noSymbol = rune(-1)
+
+// keysyms are the X11 keysym names of the printable characters which are not
+// spelled as the character itself. Only the names used by the alphanumeric
+// sections of the national layouts are listed.
+var keysyms = map[string]rune{
+	"space": ' ', "exclam": '!', "quotedbl": '"', "numbersign": '#', "dollar": '$',
+	"percent": '%', "ampersand": '&', "apostrophe": '\'', "parenleft": '(', "parenright": ')',
+	"asterisk": '*', "plus": '+', "comma": ',', "minus": '-', "period": '.', "slash": '/',
+	"colon": ':', "semicolon": ';', "less": '<', "equal": '=', "greater": '>', "question": '?',
+	"at": '@', "bracketleft": '[', "backslash": '\\', "bracketright": ']', "asciicircum": '^',
+	"underscore": '_', "grave": '`', "braceleft": '{', "bar": '|', "braceright": '}',
+	"asciitilde": '~',
+
+	"nobreakspace": '\u00a0', "exclamdown": '¡', "cent": '¢', "sterling": '£', "currency": '¤',
+	"yen": '¥', "brokenbar": '¦', "section": '§', "diaeresis": '¨', "copyright": '©',
+	"ordfeminine": 'ª', "guillemotleft": '«', "guillemetleft": '«', "notsign": '¬', "hyphen": '\u00ad',
+	"registered": '®', "macron": '¯', "degree": '°', "plusminus": '±', "twosuperior": '²',
+	"threesuperior": '³', "acute": '´', "mu": 'µ', "paragraph": '¶', "periodcentered": '·',
+	"cedilla": '¸', "onesuperior": '¹', "masculine": 'º', "guillemotright": '»', "guillemetright": '»',
+	"onequarter": '¼', "onehalf": '½', "threequarters": '¾', "questiondown": '¿',
+	"multiply": '×', "division": '÷', "ssharp": 'ß', "ydiaeresis": 'ÿ',
+	"EuroSign": '€', "numerosign": '№', "emdash": '—', "endash": '–',
+
+	"Cyrillic_io": 'ё', "Cyrillic_IO": 'Ё',
+	"ukrainian_i": 'і', "Ukrainian_I": 'І', "ukrainian_ie": 'є', "Ukrainian_IE": 'Є',
+	"ukrainian_yi": 'ї', "Ukrainian_YI": 'Ї', "ukrainian_ghe_with_upturn": 'ґ', "Ukrainian_GHE_WITH_UPTURN": 'Ґ',
+	"byelorussian_shortu": 'ў', "Byelorussian_SHORTU": 'Ў',
+
+	"Greek_finalsmallsigma": 'ς', "Greek_lambda": 'λ', "Greek_LAMBDA": 'Λ',
+	"Greek_alphaaccent": 'ά', "Greek_epsilonaccent": 'έ', "Greek_etaaccent": 'ή', "Greek_iotaaccent": 'ί',
+	"Greek_omicronaccent": 'ό', "Greek_upsilonaccent": 'ύ', "Greek_omegaaccent": 'ώ',
+}
+
+// latin1Letters are the keysyms of the Latin-1 capital letters from U+00C0, the small letters
+// from U+00E0 have the same names in lower case.
+var latin1Letters = []string{
+	"Agrave", "Aacute", "Acircumflex", "Atilde", "Adiaeresis", "Aring", "AE", "Ccedilla",
+	"Egrave", "Eacute", "Ecircumflex", "Ediaeresis", "Igrave", "Iacute", "Icircumflex", "Idiaeresis",
+	"ETH", "Ntilde", "Ograve", "Oacute", "Ocircumflex", "Otilde", "Odiaeresis", "",
+	"Oslash", "Ugrave", "Uacute", "Ucircumflex", "Udiaeresis", "Yacute", "THORN",
+}
+
+// alphabets are the keysym prefixes with the small letter names in the alphabet order.
+// The capital letters have the names in upper case.
+var alphabets = []struct {
+	prefix string
+	first  rune
+	names  []string
+}{
+	{"Cyrillic_", 'а', []string{
+		"a", "be", "ve", "ghe", "de", "ie", "zhe", "ze", "i", "shorti", "ka", "el", "em", "en", "o", "pe",
+		"er", "es", "te", "u", "ef", "ha", "tse", "che", "sha", "shcha", "hardsign", "yeru", "softsign",
+		"e", "yu", "ya",
+	}},
+	{"Greek_", 'α', []string{
+		"alpha", "beta", "gamma", "delta", "epsilon", "zeta", "eta", "theta", "iota", "kappa", "lamda",
+		"mu", "nu", "xi", "omicron", "pi", "rho", "", "sigma", "tau", "upsilon", "phi", "chi", "psi", "omega",
+	}},
+}
+
+// hebrew are the Hebrew letter keysyms from U+05D0.
+var hebrew = []string{
+	"aleph", "bet", "gimel", "dalet", "he", "waw", "zain", "chet", "tet", "yod", "finalkaph",
+	"kaph", "lamed", "finalmem", "mem", "finalnun", "nun", "samech", "ayin", "finalpe", "pe",
+	"finalzade", "zade", "qoph", "resh", "shin", "taw",
+}
+
+func init() {
+	for i, name := range latin1Letters {
+		if name == "" {
+			continue
+		}
+
+		keysyms[name] = 'À' + rune(i)
+		keysyms[strings.ToLower(name)] = 'à' + rune(i)
+	}
+
+	for _, alphabet := range alphabets {
+		for i, name := range alphabet.names {
+			if name == "" {
+				continue
+			}
+
+			small := alphabet.first + rune(i)
+			keysyms[alphabet.prefix+name] = small
+			keysyms[alphabet.prefix+strings.ToUpper(name)] = unicode.ToUpper(small)
+		}
+	}
+
+	for i, name := range hebrew {
+		keysyms["hebrew_"+name] = 'א' + rune(i)
+	}
+}
+
+// parseKeysym returns the character typed by the keysym. The character names, e.g. "q" or "1",
+// the Unicode keysyms "U0444" and "0x1000444" and the names from the table are known.
+func parseKeysym(name string) (rune, bool) {
+	if name == "NoSymbol" || name == "VoidSymbol" || strings.HasPrefix(name, "dead_") {
+		return noSymbol, true
+	}
+
+	if char, size := utf8.DecodeRuneInString(name); size == len(name) && size != 0 {
+		return char, true
+	}
+
+	if char, ok := keysyms[name]; ok {
+		return char, true
+	}
+
+	if strings.HasPrefix(name, "U") {
+		if code, err := strconv.ParseUint(name[1:], 16, 32); err == nil {
+			return rune(code), true
+		}
+	}
+
+	if strings.HasPrefix(name, "0x100") {
+		if code, err := strconv.ParseUint(name[len("0x100"):], 16, 32); err == nil {
+			return rune(code), true
+		}
+	}
+
+	return 0, false
+}
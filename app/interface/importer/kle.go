@@ -0,0 +1,278 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	pkgerr "github.com/pkg/errors"
+	"morphbits.io/app/usecase/keyboard"
+)
+
+var (
+	ErrKLESyntax   = errors.New("bad keyboard-layout-editor JSON")
+	ErrKLERotation = errors.New("rotated keys are not supported")
+)
+
+const (
+	kleDefaultAlign = 4
+	kleSpaceWidth   = 3 // Blank keys at least this wide are the space bar
+)
+
+// kleLabelPositions maps the serialized labels to the legend positions for each alignment,
+// as keyboard-layout-editor does: 0 is the top left, 1 the top center, 6 the bottom left, 8 the bottom right.
+var kleLabelPositions = [][]int{
+	{0, 6, 2, 8, 9, 11, 3, 5, 1, 4, 7, 10},
+	{1, 7, -1, -1, 9, 11, 4, -1, -1, -1, -1, 10},
+	{3, -1, 5, -1, 9, 11, -1, -1, 4, -1, -1, 10},
+	{4, -1, -1, -1, 9, 11, -1, -1, -1, -1, -1, 10},
+	{0, 6, 2, 8, 10, -1, 3, 5, 1, 4, 7, -1},
+	{1, 7, -1, -1, 10, -1, 4, -1, -1, -1, -1, -1},
+	{3, -1, 5, -1, 10, -1, -1, -1, 4, -1, -1, -1},
+	{4, -1, -1, -1, 10, -1, -1, -1, -1, -1, -1, -1},
+}
+
+// Legend positions of the characters on the key caps.
+const (
+	kleTopLeft     = 0
+	kleTopCenter   = 1
+	kleCenterLeft  = 3
+	kleCenter      = 4
+	kleBottomLeft  = 6
+	kleBottomMid   = 7
+	kleBottomRight = 8
+	kleLegends     = 12
+)
+
+// kleProps are the key properties which change the position and the size of the next keys.
+type kleProps struct {
+	X    *float64 `json:"x"`
+	Y    *float64 `json:"y"`
+	W    *float64 `json:"w"`
+	H    *float64 `json:"h"`
+	A    *int     `json:"a"`
+	R    *float64 `json:"r"`
+	Name string   `json:"name"`
+}
+
+// LoadKLE imports the raw JSON downloaded from keyboard-layout-editor.com.
+func LoadKLE(path string) (*keyboard.Definition, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, pkgerr.Wrapf(err, "failed open KLE file '%s'", path)
+	}
+
+	defer f.Close()
+
+	def, err := ParseKLE(f, strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+	if err != nil {
+		return nil, pkgerr.Wrapf(err, "failed import KLE file '%s'", path)
+	}
+
+	return def, nil
+}
+
+// ParseKLE imports the keyboard-layout-editor JSON with the exact key positions and widths.
+// The key cap with two legends has the Shift character on the top and the base character
+// on the bottom, the bottom right legend is AltGr. The single letter legend is the Shift
+// character of its lower case letter. The Shift, AltGr, Enter, Tab and the blank wide space
+// keys are recognized by the legends, the other keys without characters are skipped.
+// The fingers are not assigned.
+func ParseKLE(r io.Reader, name string) (*keyboard.Definition, error) {
+	var items []json.RawMessage
+
+	if err := json.NewDecoder(r).Decode(&items); err != nil {
+		return nil, pkgerr.Wrap(ErrKLESyntax, err.Error())
+	}
+
+	builder := newDefinitionBuilder(name)
+	align := kleDefaultAlign
+	y := 0.0
+
+	for i, item := range items {
+		var row []json.RawMessage
+
+		if err := json.Unmarshal(item, &row); err != nil {
+			// The keyboard metadata may come first
+			var meta kleProps
+			if i != 0 || json.Unmarshal(item, &meta) != nil {
+				return nil, pkgerr.Wrapf(ErrKLESyntax, "item %d is not a row", i)
+			}
+
+			if meta.Name != "" {
+				builder.metadata["description"] = meta.Name
+			}
+
+			continue
+		}
+
+		keys, err := builder.parseKLERow(row, &align, &y)
+		if err != nil {
+			return nil, pkgerr.Wrapf(err, "row %d", i)
+		}
+
+		builder.rows = append(builder.rows, keys)
+		y++
+	}
+
+	return builder.build()
+}
+
+func (b *definitionBuilder) parseKLERow(row []json.RawMessage, align *int, y *float64) ([]keyCap, error) {
+	var keys []keyCap
+
+	x, width, height := 0.0, 1.0, 1.0
+
+	for _, item := range row {
+		var label string
+		if err := json.Unmarshal(item, &label); err != nil {
+			var props kleProps
+			if err := json.Unmarshal(item, &props); err != nil {
+				return nil, pkgerr.Wrap(ErrKLESyntax, err.Error())
+			}
+
+			if props.R != nil && *props.R != 0 {
+				return nil, ErrKLERotation
+			}
+
+			x += value(props.X, 0)
+			*y += value(props.Y, 0)
+			width, height = value(props.W, width), value(props.H, height)
+
+			if props.A != nil {
+				*align = *props.A
+			}
+
+			continue
+		}
+
+		if *align < 0 || *align >= len(kleLabelPositions) {
+			return nil, pkgerr.Wrapf(ErrKLESyntax, "alignment %d", *align)
+		}
+
+		center := keyboard.Point{X: x + width/2 - 0.5, Y: *y + height/2 - 0.5} //nolint:gomnd // unit key center
+		legends := kleLegendsOf(label, *align)
+
+		if key, ok := b.kleKey(legends, center, width); ok {
+			keys = append(keys, key)
+		}
+
+		x += width
+		width, height = 1, 1
+	}
+
+	return keys, nil
+}
+
+// kleKey makes the character key from the legends, or records the modifier or the special key.
+func (b *definitionBuilder) kleKey(legends [kleLegends]string, center keyboard.Point, width float64) (keyCap, bool) {
+	key := keyCap{
+		position: center,
+		width:    width,
+		levels:   [levelsCount]rune{noSymbol, noSymbol, noSymbol},
+	}
+
+	top := firstLegend(legends, kleTopLeft, kleTopCenter)
+	bottom := firstLegend(legends, kleBottomLeft, kleBottomMid)
+
+	if bottom == "" {
+		single := firstLegend(legends, kleTopLeft, kleTopCenter, kleCenterLeft, kleCenter)
+
+		if char, ok := singleChar(single); ok {
+			key.levels[baseLevel] = unicode.ToLower(char)
+			if upper := unicode.ToUpper(char); upper != key.levels[baseLevel] {
+				key.levels[shiftLevel] = upper
+			}
+
+			return key, true
+		}
+
+		b.kleSpecial(strings.ToLower(strings.TrimSpace(single)), center, width)
+
+		return key, false
+	}
+
+	base, ok := singleChar(bottom)
+	if !ok {
+		return key, false
+	}
+
+	key.levels[baseLevel] = base
+
+	if shift, ok := singleChar(top); ok {
+		key.levels[shiftLevel] = shift
+	}
+
+	if altGr, ok := singleChar(legends[kleBottomRight]); ok {
+		key.levels[altGrLevel] = altGr
+	}
+
+	return key, true
+}
+
+// kleSpecial records the modifier or the special key position by its legend.
+func (b *definitionBuilder) kleSpecial(legend string, center keyboard.Point, width float64) {
+	switch {
+	case legend == "shift" || legend == "⇧":
+		b.shift = append(b.shift, center)
+	case legend == "altgr" || legend == "alt gr":
+		b.altGr = append(b.altGr, center)
+	case legend == "enter" || legend == "return" || legend == "↵":
+		b.special["enter"] = center
+	case legend == "tab" || legend == "⇥":
+		b.special["tab"] = center
+	case legend == "space" || (legend == "" && width >= kleSpaceWidth):
+		b.special["space"] = center
+	}
+}
+
+// kleLegendsOf splits the label into the legend positions by the alignment.
+func kleLegendsOf(label string, align int) [kleLegends]string {
+	var legends [kleLegends]string
+
+	for i, legend := range strings.Split(label, "\n") {
+		if i >= kleLegends || kleLabelPositions[align][i] < 0 {
+			continue
+		}
+
+		legends[kleLabelPositions[align][i]] = html.UnescapeString(legend)
+	}
+
+	return legends
+}
+
+func firstLegend(legends [kleLegends]string, positions ...int) string {
+	for _, position := range positions {
+		if legends[position] != "" {
+			return legends[position]
+		}
+	}
+
+	return ""
+}
+
+func singleChar(legend string) (rune, bool) {
+	char, size := utf8.DecodeRuneInString(legend)
+
+	return char, size != 0 && size == len(legend) && unicode.IsPrint(char) && char != noChar
+}
+
+func value(v *float64, fallback float64) float64 {
+	if v == nil {
+		return fallback
+	}
+
+	return *v
+}
+
+// sortKeys orders the row keys from left to right.
+func sortKeys(keys []keyCap) {
+	sort.Slice(keys, func(i, j int) bool { return keys[i].position.X < keys[j].position.X })
+}
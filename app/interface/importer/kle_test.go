@@ -0,0 +1,68 @@
+package importer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"morphbits.io/app/usecase/keyboard"
+)
+
+func Test_LoadKLE(t *testing.T) {
+	t.Parallel()
+
+	def, err := LoadKLE("testdata/ansi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if def.Name != "ansi" || def.Metadata["description"] != "ANSI 60% (trimmed)" {
+		t.Errorf("Unexpected name '%s' and description '%s'", def.Name, def.Metadata["description"])
+	}
+
+	kbd, err := keyboard.NewFromDefinition(def)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chars := map[rune]keyboard.Point{
+		'`': {X: 0, Y: 0}, '1': {X: 1, Y: 0}, '!': {X: 1, Y: 0}, 'q': {X: 1.5, Y: 1}, 'Q': {X: 1.5, Y: 1},
+		'\\': {X: 13.75, Y: 1}, 'a': {X: 1.75, Y: 2}, 'z': {X: 2.25, Y: 3}, '<': {X: 9.25, Y: 3},
+		'\n': {X: 13.375, Y: 2}, '\t': {X: 0.25, Y: 1}, ' ': {X: 6.375, Y: 4},
+	}
+
+	for char, expected := range chars {
+		if x, y, ok := kbd.Locate(char); !ok || x != expected.X || y != expected.Y {
+			t.Errorf("Expected %q at %v; got: %v, %v, %v", char, expected, x, y, ok)
+		}
+	}
+
+	if def.Modifiers == nil || len(def.Modifiers.Shift) != 2 || len(def.Modifiers.AltGr) != 1 {
+		t.Errorf("Expected 2 Shift and 1 AltGr keys; got: %+v", def.Modifiers)
+	}
+
+	// To the left Shift at 0.625, 3 then to Q
+	if dist, err := kbd.Distance('a', 'Q'); err != nil || dist != 2.125+2.875 {
+		t.Errorf("Expected 5 key widths to 'Q'; got: %v, %v", dist, err)
+	}
+}
+
+func Test_ParseKLEErrors(t *testing.T) {
+	t.Parallel()
+
+	testData := []struct {
+		Data string
+		Err  error
+	}{
+		{`{"name": "not an array"}`, ErrKLESyntax},
+		{`[["a"], {"name": "late metadata"}]`, ErrKLESyntax},
+		{`[[{"r": 15}, "a"]]`, ErrKLERotation},
+		{`[["Esc", "F1"]]`, ErrNoKeys},
+	}
+
+	for _, testCase := range testData {
+		if _, err := ParseKLE(strings.NewReader(testCase.Data), "test"); !errors.Is(err, testCase.Err) {
+			t.Errorf("%s: expected error '%v'; got: %v", testCase.Data, testCase.Err, err)
+		}
+	}
+}
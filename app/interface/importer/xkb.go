@@ -0,0 +1,288 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	pkgerr "github.com/pkg/errors"
+	"morphbits.io/app/usecase/keyboard"
+)
+
+var (
+	ErrXKBSyntax     = errors.New("bad XKB symbols")
+	ErrNoVariant     = errors.New("XKB variant not found")
+	ErrUnknownKeysym = errors.New("unknown XKB keysym")
+	ErrIncludeDepth  = errors.New("too deep XKB includes")
+)
+
+const maxIncludeDepth = 16
+
+// xkbKeys are the positions of the alphanumeric keys by the XKB key codes, on the same
+// grid as the built-in layouts: the number row starts with "1" in the column 0.
+var xkbKeys = func() map[string]keyboard.Point {
+	keys := map[string]keyboard.Point{
+		"TLDE": {X: -1, Y: 0},
+		"BKSL": {X: 12, Y: 1}, //nolint:gomnd // after the top row
+	}
+
+	for row, rowKeys := range []struct {
+		prefix string
+		count  int
+	}{{"AE", 12}, {"AD", 12}, {"AC", 11}, {"AB", 10}} {
+		for i := 1; i <= rowKeys.count; i++ {
+			keys[fmt.Sprintf("%s%02d", rowKeys.prefix, i)] = keyboard.Point{X: float64(i - 1), Y: float64(row)}
+		}
+	}
+
+	return keys
+}()
+
+// xkbAltGr is the right Alt key position, to the right of the space bar.
+var xkbAltGr = keyboard.Point{X: 7, Y: 4}
+
+// xkbModifierFiles are the included symbols files which have no alphanumeric keys,
+// they are skipped if missing.
+var xkbModifierFiles = map[string]bool{
+	"level3": true, "level5": true, "lv3": true, "lv5": true, "group": true, "shift": true,
+	"ctrl": true, "capslock": true, "compose": true, "keypad": true, "kpdl": true, "nbsp": true,
+	"eurosign": true, "rupeesign": true, "altwin": true, "pc": true, "inet": true,
+}
+
+var (
+	xkbSection   = regexp.MustCompile(`((?:\w+\s+)*)xkb_symbols\s+"([^"]*)"\s*\{`)
+	xkbStatement = regexp.MustCompile(
+		`(?s)(?:(include|override|augment|replace)\s+"([^"]*)")|(?:key\s*<(\w+)>\s*\{(.*?)\}\s*;)`)
+	xkbName        = regexp.MustCompile(`name\[\w+\]\s*=\s*"([^"]*)"`)
+	xkbGroup1      = regexp.MustCompile(`symbols\[Group1\]\s*=\s*\[([^\]]*)\]`)
+	xkbAssignments = regexp.MustCompile(`\w+\[\w+\]\s*=\s*(?:\[[^\]]*\]|"[^"]*")|\w+\s*=\s*"[^"]*"`)
+	xkbList        = regexp.MustCompile(`\[([^\]]*)\]`)
+	xkbComment     = regexp.MustCompile(`//[^\n]*`)
+)
+
+// xkbSymbols is the variant section of the symbols file, the keysyms for each key code.
+type xkbSymbols struct {
+	description string
+	keys        map[string][]string
+}
+
+// LoadXKB imports the X11 XKB symbols file, e.g. /usr/share/X11/xkb/symbols/de. The variant
+// is selected as in the XKB includes, "de(nodeadkeys)", the default variant is used without it.
+// The includes are searched in the same directory. Only the alphanumeric keys are imported:
+// the first level is the base layer, the second is Shift and the third is AltGr. The keys are
+// placed on the integer grid with the standard Shift, AltGr and special keys and fingers.
+func LoadXKB(spec string) (*keyboard.Definition, error) {
+	path, variant := splitXKBSpec(spec)
+
+	symbols, err := loadXKBSymbols(filepath.Dir(path), filepath.Base(path), variant, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	name := filepath.Base(path)
+	if variant != "" {
+		name += "(" + variant + ")"
+	}
+
+	builder := newDefinitionBuilder(name)
+	builder.shift = keyboard.ShiftKeys()
+	builder.altGr = []keyboard.Point{xkbAltGr}
+	builder.special = keyboard.SpecialKeys()
+
+	if symbols.description != "" {
+		builder.metadata["description"] = symbols.description
+	}
+
+	rows := make([][]keyCap, 4) //nolint:gomnd // number, top, home and bottom rows
+
+	for code, position := range xkbKeys {
+		names, ok := symbols.keys[code]
+		if !ok {
+			continue
+		}
+
+		key := keyCap{
+			position: position,
+			width:    1,
+			levels:   [levelsCount]rune{noSymbol, noSymbol, noSymbol},
+		}
+
+		for level := 0; level < levelsCount && level < len(names); level++ {
+			char, ok := parseKeysym(names[level])
+			if !ok {
+				return nil, pkgerr.Wrapf(ErrUnknownKeysym, "'%s' on <%s> in '%s'", names[level], code, spec)
+			}
+
+			key.levels[level] = char
+		}
+
+		rows[int(position.Y)] = append(rows[int(position.Y)], key)
+	}
+
+	for _, row := range rows {
+		sortKeys(row)
+	}
+
+	builder.rows = rows
+
+	def, err := builder.build()
+	if err != nil {
+		return nil, pkgerr.Wrapf(err, "failed import '%s'", spec)
+	}
+
+	return def.WithStandardFingers(), nil
+}
+
+// splitXKBSpec splits "path(variant)".
+func splitXKBSpec(spec string) (string, string) {
+	if open := strings.LastIndex(spec, "("); open > 0 && strings.HasSuffix(spec, ")") {
+		return spec[:open], spec[open+1 : len(spec)-1]
+	}
+
+	return spec, ""
+}
+
+func loadXKBSymbols(dir, file, variant string, depth int) (*xkbSymbols, error) {
+	if depth > maxIncludeDepth {
+		return nil, pkgerr.Wrapf(ErrIncludeDepth, "'%s(%s)'", file, variant)
+	}
+
+	path := filepath.Join(dir, file)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, pkgerr.Wrapf(err, "failed read XKB symbols '%s'", path)
+	}
+
+	body, err := findXKBSection(xkbComment.ReplaceAllString(string(data), ""), variant)
+	if err != nil {
+		return nil, pkgerr.Wrapf(err, "'%s'", path)
+	}
+
+	symbols := &xkbSymbols{
+		description: "",
+		keys:        make(map[string][]string),
+	}
+
+	if match := xkbName.FindStringSubmatch(body); match != nil {
+		symbols.description = match[1]
+	}
+
+	for _, match := range xkbStatement.FindAllStringSubmatch(body, -1) {
+		if match[1] != "" {
+			if err := symbols.include(dir, match[2], depth); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		if names := parseXKBKey(match[4]); names != nil {
+			symbols.keys[match[3]] = names
+		}
+	}
+
+	return symbols, nil
+}
+
+// include merges the included "file(variant)" sections, the later keys override the earlier ones.
+func (s *xkbSymbols) include(dir, spec string, depth int) error {
+	for _, part := range strings.Split(spec, "+") {
+		file, variant := splitXKBSpec(strings.TrimSpace(part))
+		if file == "" {
+			continue
+		}
+
+		if _, err := os.Stat(filepath.Join(dir, file)); err != nil && xkbModifierFiles[file] {
+			continue
+		}
+
+		included, err := loadXKBSymbols(dir, file, variant, depth+1)
+		if err != nil {
+			return err
+		}
+
+		for code, names := range included.keys {
+			s.keys[code] = names
+		}
+
+		if s.description == "" {
+			s.description = included.description
+		}
+	}
+
+	return nil
+}
+
+// findXKBSection returns the body of the variant section, the default one if the variant is empty.
+func findXKBSection(data, variant string) (string, error) {
+	var fallback string
+
+	for _, loc := range xkbSection.FindAllStringSubmatchIndex(data, -1) {
+		flags, name := data[loc[2]:loc[3]], data[loc[4]:loc[5]]
+
+		end := matchBrace(data, loc[1]-1)
+		if end < 0 {
+			return "", pkgerr.Wrapf(ErrXKBSyntax, "unclosed section '%s'", name)
+		}
+
+		body := data[loc[1]:end]
+
+		if name == variant || (variant == "" && strings.Contains(flags, "default")) {
+			return body, nil
+		}
+
+		if fallback == "" {
+			fallback = body
+		}
+	}
+
+	if variant == "" && fallback != "" {
+		return fallback, nil
+	}
+
+	return "", pkgerr.Wrapf(ErrNoVariant, "'%s'", variant)
+}
+
+// matchBrace returns the index of the brace closing the one at the open index.
+func matchBrace(data string, open int) int {
+	depth := 0
+
+	for i := open; i < len(data); i++ {
+		switch data[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// parseXKBKey returns the first group keysyms of the key, nil if there are none.
+func parseXKBKey(body string) []string {
+	list := ""
+
+	if match := xkbGroup1.FindStringSubmatch(body); match != nil {
+		list = match[1]
+	} else if match := xkbList.FindStringSubmatch(xkbAssignments.ReplaceAllString(body, "")); match != nil {
+		list = match[1]
+	}
+
+	var names []string
+
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
@@ -0,0 +1,101 @@
+package importer
+
+import (
+	"errors"
+	"testing"
+
+	"morphbits.io/app/usecase/keyboard"
+)
+
+func Test_LoadXKB(t *testing.T) {
+	t.Parallel()
+
+	testData := []struct {
+		Spec        string
+		Name        string
+		Description string
+		Chars       map[rune]keyboard.Point
+		Missing     string
+	}{
+		{
+			Spec:        "testdata/xkb/us",
+			Name:        "us",
+			Description: "English (US)",
+			Chars: map[rune]keyboard.Point{
+				'1': {X: 0, Y: 0}, '`': {X: -1, Y: 0}, '~': {X: -1, Y: 0}, 'q': {X: 0, Y: 1},
+				'|': {X: 12, Y: 1}, 'A': {X: 0, Y: 2}, '\'': {X: 10, Y: 2}, '?': {X: 9, Y: 3},
+			},
+			Missing: "é",
+		},
+		{
+			Spec:        "testdata/xkb/us(intl)",
+			Name:        "us(intl)",
+			Description: "English (US, intl., with dead keys)",
+			Chars: map[rune]keyboard.Point{
+				'é': {X: 2, Y: 1}, 'á': {X: 0, Y: 2}, '²': {X: 1, Y: 0}, '¼': {X: 5, Y: 0},
+				'@': {X: 1, Y: 0}, 'q': {X: 0, Y: 1},
+			},
+			Missing: "`'^",
+		},
+		{
+			Spec:        "testdata/xkb/ru",
+			Name:        "ru",
+			Description: "Russian",
+			Chars: map[rune]keyboard.Point{
+				'ё': {X: -1, Y: 0}, '№': {X: 2, Y: 0}, 'й': {X: 0, Y: 1}, 'Ъ': {X: 11, Y: 1},
+				'э': {X: 10, Y: 2}, 'я': {X: 0, Y: 3}, ',': {X: 9, Y: 3},
+			},
+			Missing: "q",
+		},
+	}
+
+	for _, testCase := range testData {
+		def, err := LoadXKB(testCase.Spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if def.Name != testCase.Name || def.Metadata["description"] != testCase.Description {
+			t.Errorf("Unexpected name '%s' and description '%s'", def.Name, def.Metadata["description"])
+		}
+
+		kbd, err := keyboard.NewFromDefinition(def)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for char, expected := range testCase.Chars {
+			if x, y, ok := kbd.Locate(char); !ok || x != expected.X || y != expected.Y {
+				t.Errorf("%s: expected '%c' at %v; got: %v, %v, %v", testCase.Spec, char, expected, x, y, ok)
+			}
+		}
+
+		for _, char := range testCase.Missing {
+			if kbd.IsMapped(char) {
+				t.Errorf("%s: expected '%c' to be unmapped", testCase.Spec, char)
+			}
+		}
+
+		if _, err := keyboard.NewTouchTyping(kbd, 1); err != nil {
+			t.Errorf("%s: expected the standard fingers; got: %v", testCase.Spec, err)
+		}
+	}
+}
+
+func Test_LoadXKBErrors(t *testing.T) {
+	t.Parallel()
+
+	testData := []struct {
+		Spec string
+		Err  error
+	}{
+		{"testdata/xkb/ru(broken)", ErrUnknownKeysym},
+		{"testdata/xkb/ru(typewriter)", ErrNoVariant},
+	}
+
+	for _, testCase := range testData {
+		if _, err := LoadXKB(testCase.Spec); !errors.Is(err, testCase.Err) {
+			t.Errorf("%s: expected error '%v'; got: %v", testCase.Spec, testCase.Err, err)
+		}
+	}
+}
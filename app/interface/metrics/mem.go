@@ -3,12 +3,14 @@ package metrics
 type Metrics struct {
 	TotalWords    int
 	FilteredWords int
+	SkippedWords  int
 }
 
 func New() *Metrics {
 	return &Metrics{
 		TotalWords:    0,
 		FilteredWords: 0,
+		SkippedWords:  0,
 	}
 }
 
@@ -20,9 +22,14 @@ func (m *Metrics) IncFilteredWords() {
 	m.FilteredWords++
 }
 
+func (m *Metrics) IncSkippedWords() {
+	m.SkippedWords++
+}
+
 func (m *Metrics) GetMetrics() map[string]any {
 	return map[string]any{
 		"total_words":    m.TotalWords,
 		"filtered_words": m.FilteredWords,
+		"skipped_words":  m.SkippedWords,
 	}
 }
@@ -0,0 +1,88 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	pkgerr "github.com/pkg/errors"
+	"morphbits.io/app/usecase/keyboard"
+)
+
+const asciiKeyWidth = 4 // Terminal columns per key width
+
+// ASCII draws the keyboard for the terminal. The typed keys are bracketed, the number
+// under a key is the keystroke which reaches it first, "+" marks the keys typed again.
+// The numbered steps with their arrows and costs are listed below the keyboard.
+func (r *Route) ASCII(w io.Writer) error {
+	minX, minY, maxX, maxY := r.bounds()
+
+	rows := int(math.Round(maxY-minY)) + 1
+	width := int(math.Round((maxX-minX)*asciiKeyWidth)) + asciiKeyWidth
+
+	lines := make([][]rune, 2*rows)
+	for i := range lines {
+		lines[i] = []rune(strings.Repeat(" ", width))
+	}
+
+	visits := make(map[Key][]int)
+	for i, key := range r.Typed {
+		visits[key] = append(visits[key], i+1)
+	}
+
+	for _, key := range r.Keys {
+		row := int(math.Round(key.Y - minY))
+		col := int(math.Round((key.X - minX) * asciiKeyWidth))
+
+		keyCap := " " + string(key.Label) + " "
+		mark := ""
+
+		if keystrokes, ok := visits[key]; ok {
+			keyCap = "[" + string(key.Label) + "]"
+			mark = strconv.Itoa(keystrokes[0])
+
+			if len(keystrokes) > 1 {
+				mark += "+"
+			}
+		}
+
+		put(lines[2*row], col, keyCap)
+		put(lines[2*row+1], col+1, mark)
+	}
+
+	var sb strings.Builder
+
+	for _, line := range lines {
+		sb.WriteString(strings.TrimRight(string(line), " "))
+		sb.WriteByte('\n')
+	}
+
+	fmt.Fprintf(&sb, "\n%s\n", r.Pass)
+
+	for i, step := range r.Steps {
+		fmt.Fprintf(&sb, "%2d. %s %s %s  %d\n", i+1,
+			keyboard.KeyName(step.From.Label), step.Arrow(), keyboard.KeyName(step.To.Label), step.Cost)
+	}
+
+	for _, line := range r.keystrokes() {
+		fmt.Fprintf(&sb, "    %s\n", line)
+	}
+
+	fmt.Fprintf(&sb, "Total: %d\n", r.Total)
+
+	_, err := io.WriteString(w, sb.String())
+
+	return pkgerr.Wrap(err, "failed write ASCII route")
+}
+
+func put(line []rune, col int, text string) {
+	for _, char := range text {
+		if col >= 0 && col < len(line) {
+			line[col] = char
+		}
+
+		col++
+	}
+}
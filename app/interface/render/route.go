@@ -0,0 +1,153 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	pkgerr "github.com/pkg/errors"
+	"morphbits.io/app/usecase/keyboard"
+)
+
+var ErrCosts = errors.New("keystroke costs don't match the pass")
+
+type Locator interface {
+	Locate(char rune) (x, y float64, ok bool)
+}
+
+// Key is the key cap drawn on the keyboard.
+type Key struct {
+	Label rune
+	X, Y  float64
+}
+
+// Step is the finger travel from one key to the next one.
+type Step struct {
+	From, To Key
+	Cost     int
+}
+
+// Route is the keyboard with the path of the typed passphrase.
+type Route struct {
+	Pass  string
+	Keys  []Key
+	Typed []Key // Keys in the typing order
+	Steps []Step
+	First int // Cost of the first keystroke, the travel from the start key included
+	End   int // Cost of the end key, zero without it
+	Total int
+}
+
+// NewRoute lays the passphrase over the layout. Every character is typed, the separators
+// included, as in the passphrase search. The positions are taken from the locator. The costs
+// are of every keystroke, the end key is the last one if there is any, they are measured
+// by the search, so the route total is the pass cost.
+func NewRoute(layout keyboard.Layout, locator Locator, pass string, costs []int) (*Route, error) {
+	typed := []rune(pass)
+	if len(typed) == 0 || len(costs) < len(typed) || len(costs) > len(typed)+1 {
+		return nil, pkgerr.Wrapf(ErrCosts, "%d costs of '%s'", len(costs), pass)
+	}
+
+	route := &Route{
+		Pass:  pass,
+		Keys:  nil,
+		Typed: nil,
+		Steps: nil,
+		First: costs[0],
+		End:   0,
+		Total: costs[0],
+	}
+
+	for _, row := range layout {
+		for _, char := range row {
+			if x, y, ok := locator.Locate(char); ok {
+				route.Keys = append(route.Keys, Key{Label: char, X: x, Y: y})
+			}
+		}
+	}
+
+	for _, char := range typed {
+		key, err := locate(layout, locator, char)
+		if err != nil {
+			return nil, err
+		}
+
+		route.Typed = append(route.Typed, key)
+	}
+
+	for i := 1; i < len(typed); i++ {
+		route.Steps = append(route.Steps, Step{From: route.Typed[i-1], To: route.Typed[i], Cost: costs[i]})
+		route.Total += costs[i]
+	}
+
+	if len(costs) > len(typed) {
+		route.End = costs[len(typed)]
+		route.Total += route.End
+	}
+
+	return route, nil
+}
+
+// locate finds the key cap of the character, e.g. 'a' for 'A' typed with Shift.
+func locate(layout keyboard.Layout, locator Locator, char rune) (Key, error) {
+	x, y, ok := locator.Locate(char)
+	if !ok {
+		return Key{Label: 0, X: 0, Y: 0}, &keyboard.UnknownKeyError{Key: char}
+	}
+
+	for _, row := range layout {
+		for _, label := range row {
+			if lx, ly, ok := locator.Locate(label); ok && lx == x && ly == y {
+				return Key{Label: label, X: x, Y: y}, nil
+			}
+		}
+	}
+
+	return Key{Label: char, X: x, Y: y}, nil
+}
+
+// Arrow returns the direction of the step as the arrow character.
+func (s *Step) Arrow() string {
+	dx, dy := s.To.X-s.From.X, s.To.Y-s.From.Y
+	if dx == 0 && dy == 0 {
+		return "↺"
+	}
+
+	// Screen Y goes down, so the angle is counted clockwise from the right
+	arrows := []string{"→", "↘", "↓", "↙", "←", "↖", "↑", "↗"}
+	sector := int(math.Round(math.Atan2(dy, dx)/(math.Pi/4))+8) % 8
+
+	return arrows[sector]
+}
+
+// keystrokes lists the costs of the first keystroke and the end key which are not the steps,
+// the free ones are left out.
+func (r *Route) keystrokes() []string {
+	var lines []string
+
+	if r.First != 0 {
+		lines = append(lines, fmt.Sprintf("first key  %d", r.First))
+	}
+
+	if r.End != 0 {
+		lines = append(lines, fmt.Sprintf("end key  %d", r.End))
+	}
+
+	return lines
+}
+
+func (r *Route) bounds() (minX, minY, maxX, maxY float64) {
+	minX, minY = math.Inf(1), math.Inf(1)
+	maxX, maxY = math.Inf(-1), math.Inf(-1)
+
+	for _, key := range r.Keys {
+		minX, minY = math.Min(minX, key.X), math.Min(minY, key.Y)
+		maxX, maxY = math.Max(maxX, key.X), math.Max(maxY, key.Y)
+	}
+
+	if len(r.Keys) == 0 {
+		return 0, 0, 0, 0
+	}
+
+	return minX, minY, maxX, maxY
+}
@@ -0,0 +1,183 @@
+package render
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"morphbits.io/app/usecase/app"
+	mockApp "morphbits.io/app/usecase/app/mock"
+	"morphbits.io/app/usecase/keyboard"
+)
+
+// newTestRoute lays the pass typed without the start and end keys over QWERTY.
+func newTestRoute(t *testing.T, calc app.DistanceCalculator, pass string) (*Route, error) {
+	t.Helper()
+
+	terminals := app.Terminals{Start: 0, End: 0, Separator: app.Separator{Kind: app.NoSeparator, Text: ""}}
+
+	costs, err := app.KeystrokeCosts(pass, &terminals, calc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	locator, ok := calc.(Locator)
+	if !ok {
+		t.Fatalf("Expected the key positions of %T", calc)
+	}
+
+	return NewRoute(keyboard.QWERTY(), locator, pass, costs)
+}
+
+func Test_Route(t *testing.T) {
+	t.Parallel()
+
+	kbd, err := keyboard.NewQWERTY()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	route, err := newTestRoute(t, kbd, "as Sd")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		From, To, Arrow string
+		Cost            int
+	}{
+		{"a", "s", "→", 1},
+		{"s", " ", "↘", 6},
+		{" ", "s", "↖", 10}, // Through the left Shift
+		{"s", "d", "→", 1},
+	}
+
+	if len(route.Steps) != len(expected) || route.Total != 18 {
+		t.Fatalf("Unexpected route: %+v", route)
+	}
+
+	for i, step := range route.Steps {
+		if string(step.From.Label) != expected[i].From || string(step.To.Label) != expected[i].To ||
+			step.Arrow() != expected[i].Arrow || step.Cost != expected[i].Cost {
+			t.Errorf("Step %d: expected %+v; got: %c %s %c %d",
+				i+1, expected[i], step.From.Label, step.Arrow(), step.To.Label, step.Cost)
+		}
+	}
+
+	var ascii strings.Builder
+	if err := route.ASCII(&ascii); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, part := range []string{"[a]", "[s]", " 2+", " 2. s ↘ space  6", " 4. s → d  1", "Total: 18"} {
+		if !strings.Contains(ascii.String(), part) {
+			t.Errorf("Expected %q in:\n%s", part, ascii.String())
+		}
+	}
+
+	var svg strings.Builder
+	if err := route.SVG(&svg); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Count(svg.String(), "<line") != 4 || strings.Count(svg.String(), "<circle") != 0 {
+		t.Errorf("Expected 4 arrows and no loop in:\n%s", svg.String())
+	}
+
+	// The same key through the Shift is the loop
+	if route, err = newTestRoute(t, kbd, "sS"); err != nil {
+		t.Fatal(err)
+	}
+
+	svg.Reset()
+
+	if err := route.SVG(&svg); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Count(svg.String(), "<circle") != 1 {
+		t.Errorf("Expected 1 loop in:\n%s", svg.String())
+	}
+}
+
+func Test_RouteUnknownKey(t *testing.T) {
+	t.Parallel()
+
+	kbd, err := keyboard.NewQWERTY()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewRoute(keyboard.QWERTY(), kbd, "abж", []int{0, 1, 1}); !errors.Is(err, keyboard.ErrUnknownKey) {
+		t.Errorf("Expected unknown key error; got: %v", err)
+	}
+
+	if _, err := NewRoute(keyboard.QWERTY(), kbd, "ab", []int{0}); !errors.Is(err, ErrCosts) {
+		t.Errorf("Expected keystroke costs error; got: %v", err)
+	}
+}
+
+func Test_RouteTouch(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	kbd, err := keyboard.NewQWERTY()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	touch, err := keyboard.NewTouchTyping(kbd, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dictReader := mockApp.NewMockDictReader(ctrl)
+	read := func(_ context.Context, handler func(string) error) error {
+		for _, word := range []string{"asdfg", "hjkl", "qwert", "poiuy", "zxcvb"} {
+			if err := handler(word); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	dictReader.EXPECT().Run(gomock.Any(), gomock.Any()).DoAndReturn(read).Times(2)
+
+	metrics := mockApp.NewMockMetrics(ctrl)
+	metrics.EXPECT().IncWords().AnyTimes()
+	metrics.EXPECT().IncFilteredWords().AnyTimes()
+
+	for _, terminals := range []app.Terminals{
+		{Start: 0, End: 0, Separator: app.Separator{Kind: app.HyphenSeparator, Text: ""}},
+		{Start: 'g', End: '\n', Separator: app.Separator{Kind: app.HyphenSeparator, Text: ""}},
+	} {
+		config := app.DefaultConfig()
+		config.Terminals = terminals
+
+		result, err := app.New(metrics, dictReader, touch, config).Run(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		pass := result.Passes[0]
+
+		costs, err := app.KeystrokeCosts(pass.Text, &terminals, touch)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		route, err := NewRoute(keyboard.QWERTY(), touch, pass.Text, costs)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if route.Total != pass.Cost || route.First != pass.Start || route.End != pass.End {
+			t.Errorf("Expected the route of '%s' to cost %d; got: %+v", pass.Text, pass.Cost, route)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package render
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"strings"
+
+	pkgerr "github.com/pkg/errors"
+	"morphbits.io/app/usecase/keyboard"
+)
+
+const (
+	svgUnit       = 48 // Pixels per key width
+	svgMargin     = 24
+	svgLineHeight = 18
+	svgArrowGap   = 0.3 // Arrows start and end this far from the key centers, in key widths
+)
+
+// SVG draws the keyboard with the numbered arrows of the steps, the step costs
+// are listed below the keyboard.
+func (r *Route) SVG(w io.Writer) error {
+	minX, minY, maxX, maxY := r.bounds()
+
+	keysHeight := (maxY-minY+1)*svgUnit + svgMargin
+	width := (maxX-minX+1)*svgUnit + 2*svgMargin
+	height := keysHeight + float64((len(r.Steps)+len(r.keystrokes())+2)*svgLineHeight) + svgMargin
+
+	cx := func(x float64) float64 { return svgMargin + (x-minX+0.5)*svgUnit }
+	cy := func(y float64) float64 { return svgMargin + (y-minY+0.5)*svgUnit }
+
+	typed := make(map[Key]bool)
+	for _, key := range r.Typed {
+		typed[key] = true
+	}
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" font-family="monospace">`+"\n",
+		width, height)
+	sb.WriteString(`<defs><marker id="arrow" viewBox="0 0 10 10" refX="9" refY="5" markerWidth="6" markerHeight="6" ` +
+		`orient="auto-start-reverse"><path d="M0,0 L10,5 L0,10 z" fill="#c0392b"/></marker></defs>` + "\n")
+
+	for _, key := range r.Keys {
+		fill := "#f4f4f4"
+		if typed[key] {
+			fill = "#ffe9a8"
+		}
+
+		fmt.Fprintf(&sb, `<rect x="%.1f" y="%.1f" width="%d" height="%d" rx="6" fill="%s" stroke="#888"/>`+"\n",
+			cx(key.X)-svgUnit/2+2, cy(key.Y)-svgUnit/2+2, svgUnit-4, svgUnit-4, fill)
+		fmt.Fprintf(&sb, `<text x="%.1f" y="%.1f" font-size="16" text-anchor="middle" dominant-baseline="central">%s</text>`+"\n",
+			cx(key.X), cy(key.Y), html.EscapeString(string(key.Label)))
+	}
+
+	for i, step := range r.Steps {
+		x1, y1, x2, y2 := cx(step.From.X), cy(step.From.Y), cx(step.To.X), cy(step.To.Y)
+		labelX, labelY := (x1+x2)/2, (y1+y2)/2
+
+		if dist := math.Hypot(x2-x1, y2-y1); dist == 0 {
+			// The same key again: the loop over the top right corner
+			fmt.Fprintf(&sb, `<circle cx="%.1f" cy="%.1f" r="%d" fill="none" stroke="#c0392b"/>`+"\n",
+				x1+svgUnit/3, y1-svgUnit/3, svgUnit/6)
+			labelX, labelY = x1+svgUnit/3, y1-svgUnit/3
+		} else {
+			gap := svgArrowGap * svgUnit / dist
+			fmt.Fprintf(&sb, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#c0392b" stroke-width="2" marker-end="url(#arrow)"/>`+"\n",
+				x1+(x2-x1)*gap, y1+(y2-y1)*gap, x2-(x2-x1)*gap, y2-(y2-y1)*gap)
+		}
+
+		fmt.Fprintf(&sb, `<text x="%.1f" y="%.1f" font-size="11" fill="#c0392b" text-anchor="middle">%d</text>`+"\n",
+			labelX, labelY-3, i+1)
+	}
+
+	lineY := keysHeight + svgLineHeight
+	fmt.Fprintf(&sb, `<text x="%d" y="%.1f" font-size="14">%s  (total %d)</text>`+"\n",
+		svgMargin, lineY, html.EscapeString(r.Pass), r.Total)
+
+	for i, step := range r.Steps {
+		lineY += svgLineHeight
+		fmt.Fprintf(&sb, `<text x="%d" y="%.1f" font-size="12">%d. %s %s %s  %d</text>`+"\n",
+			svgMargin, lineY, i+1, html.EscapeString(keyboard.KeyName(step.From.Label)), step.Arrow(),
+			html.EscapeString(keyboard.KeyName(step.To.Label)), step.Cost)
+	}
+
+	for _, line := range r.keystrokes() {
+		lineY += svgLineHeight
+		fmt.Fprintf(&sb, `<text x="%d" y="%.1f" font-size="12">%s</text>`+"\n", svgMargin, lineY, line)
+	}
+
+	sb.WriteString("</svg>\n")
+
+	_, err := io.WriteString(w, sb.String())
+
+	return pkgerr.Wrap(err, "failed write SVG route")
+}
@@ -1,212 +1,257 @@
 package app
 
 import (
-	"fmt"
+	"context"
+	"crypto/rand"
+	"errors"
 	"sort"
 	"strings"
-	"sync"
+	"time"
 
 	pkgerr "github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
-	"morphbits.io/app/usecase/combin"
-	"morphbits.io/app/usecase/utils"
 )
 
+// partialSearchTime limits the search of the words read before the context is done.
+const partialSearchTime = time.Second
+
 type App struct {
 	dictReader DictReader
 	calc       DistanceCalculator
 	metrics    Metrics
+	config     Config
 
-	words wordLenMap
+	words      wordClasses
+	vocabulary map[string]struct{} // All the usable words, for the dictionary entropy
 }
 
-func New(metrics Metrics, dictReader DictReader, calc DistanceCalculator) *App {
+func New(metrics Metrics, dictReader DictReader, calc DistanceCalculator, config Config) *App {
 	return &App{
 		dictReader: dictReader,
 		calc:       calc,
 		metrics:    metrics,
+		config:     config,
 
-		words: make(wordLenMap),
+		words:      make(wordClasses),
+		vocabulary: make(map[string]struct{}),
 	}
 }
 
-func (app *App) Run() error {
-	if err := app.dictReader.Run(app.handleWord); err != nil {
-		return pkgerr.Wrap(err, "failed read dictionary")
+// Run reads the dictionary and finds the cheapest passphrases or picks a random one, or generates
+// the PINs in the PIN mode. The result is checked against the target strength.
+// When the context is done during the search, the best result found so far is returned marked partial.
+func (app *App) Run(ctx context.Context) (*Result, error) {
+	if err := app.config.Validate(); err != nil {
+		return nil, err
 	}
 
-	distDict := make([]int, 0, len(app.words))
-	for i := range app.words {
-		distDict = append(distDict, i)
+	result, err := app.run(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	bestPass := getBestPass(distDict, app.words, app.calc)
-
-	for i := 0; i < len(bestPass); i++ {
-		log.WithFields(log.Fields{
-			"pass": bestPass[i].Data,
-			"dist": bestPass[i].Dist,
-		}).Info("Best pass")
+	result.Entropy = analyzeEntropy(&app.config, len(app.vocabulary), result.Pool)
+	if err := result.Entropy.check(&app.config.Strength); err != nil {
+		return nil, err
 	}
 
-	return nil
+	return result, nil
 }
 
-func (app *App) handleWord(rawWord string) error {
-	word := strings.ToLower(rawWord)
+func (app *App) run(ctx context.Context) (*Result, error) {
+	if app.config.Mode == PINMode {
+		return app.runPIN(ctx)
+	}
 
-	dist, err := calcInternalDistance(word, app.calc)
+	if err := app.config.Terminals.validate(app.calc); err != nil {
+		return nil, err
+	}
+
+	complete, err := app.readDictionary(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	length := len(word)
+	run := app.runBest
+	if app.config.Pass.Random {
+		run = app.runRandom
+	}
 
-	app.metrics.IncWords()
+	// The context is done already after the interrupted reading, the words read get a search of their own
+	searchCtx := ctx
+	if !complete {
+		var cancel context.CancelFunc
+		searchCtx, cancel = context.WithTimeout(context.Background(), partialSearchTime)
 
-	shortestWords := app.words[length]
-	i := sort.Search(len(shortestWords), func(i int) bool { return shortestWords[i].Dist >= dist })
-
-	if i < len(shortestWords) {
-		// Filter words with similar length, similar internal distance
-		// and similar start & stop. They have the same
-		// distance between the neightbour words.
-		foundWord := shortestWords[i].Data
-		if shortestWords[i].Dist == dist &&
-			foundWord[0] == rawWord[0] &&
-			foundWord[len(foundWord)-1] == rawWord[len(rawWord)-1] {
-			return nil
-		}
+		defer cancel()
 	}
 
-	shortestWords = utils.Insert(shortestWords, wItem{
-		Data: word,
-		Dist: dist,
-	}, i)
-
-	end := len(shortestWords)
-	if end > bestWordsCount {
-		end = bestWordsCount
-	} else {
-		app.metrics.IncFilteredWords()
+	result, err := run(searchCtx)
+	if err != nil {
+		return nil, err
 	}
 
-	shortestWords = shortestWords[:end]
-	app.words[length] = shortestWords
+	result.Partial = result.Partial || !complete
 
-	return nil
+	return result, nil
 }
 
-// getBestPass looks for the best word sequences in the each group of words.
-func getBestPass(distDict []int, words wordLenMap, calc DistanceCalculator) []wItem {
-	lenCombinations := getLenCombinations(distDict, passWords, minPassLength, maxPassLength)
-	bestDist := utils.MaxInt()
+// readDictionary reads the words until the context is done.
+// It reports whether all the words are read.
+func (app *App) readDictionary(ctx context.Context) (bool, error) {
+	err := app.dictReader.Run(ctx, app.handleWord)
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false, nil
+	}
 
-	var (
-		bestPass []wItem
-		wg       sync.WaitGroup
-		lock     sync.Mutex
-	)
+	if err != nil {
+		return false, pkgerr.Wrap(err, "failed read dictionary")
+	}
 
-	for i := 0; i < len(lenCombinations); i++ {
-		lenComb := (*[passWords]int)(lenCombinations[i])
+	return true, nil
+}
 
-		wg.Add(1)
+func (app *App) runBest(ctx context.Context) (*Result, error) {
+	passes, partial, err := getBestPass(
+		ctx, app.words, app.calc, &app.config.Terminals, &app.config.Pass, &app.config.Compose)
+	if err != nil {
+		return nil, err
+	}
 
-		go func() {
-			defer wg.Done()
+	result := newResult(&app.config, app.calc, passes)
+	result.Partial = partial
 
-			pass, err := getBestPassInGroup(getWords(words, *lenComb), calc, uniqueWords)
-			if err != nil {
-				log.Fatal(err)
-			}
+	return result, nil
+}
 
-			lock.Lock()
-			defer lock.Unlock()
+func (app *App) runRandom(ctx context.Context) (*Result, error) {
+	pass, pool, partial, err := getRandomPass(
+		ctx, app.words, app.calc, &app.config.Terminals, &app.config.Pass, &app.config.Compose, rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	result := newResult(&app.config, app.calc, []Pass{*pass})
+	result.Pool = pool
+	result.Partial = partial
+
+	return result, nil
+}
 
-			if pass.Dist == bestDist {
-				bestPass = append(bestPass, *pass)
-			}
+func (app *App) runPIN(ctx context.Context) (*Result, error) {
+	bestPINs, partial, err := getBestPIN(ctx, app.calc, &app.config.PIN)
+	if err != nil {
+		return nil, pkgerr.Wrap(err, "failed generate PIN")
+	}
 
-			if pass.Dist < bestDist {
-				bestDist = pass.Dist
-				bestPass = []wItem{*pass}
-			}
-		}()
+	passes := make([]Pass, 0, len(bestPINs))
+	for i := 0; i < len(bestPINs); i++ {
+		passes = append(passes, pinPass(bestPINs[i]))
 	}
 
-	wg.Wait()
+	result := newResult(&app.config, app.calc, passes)
+	result.Partial = partial
 
-	return bestPass
+	return result, nil
 }
 
-// getBestPassInGroup looks for the best combination within the group of words.
-func getBestPassInGroup(words *[passWords][]wItem, calc DistanceCalculator, unique bool) (*wItem, error) {
-	var password *wItem
+func (app *App) handleWord(rawWord string) error {
+	app.metrics.IncWords()
 
-	bestDist := utils.MaxInt()
-	dict := utils.MakeRange(0, bestWordsCount-1)
+	word, err := app.prepareWord(rawWord)
+	if err != nil {
+		return err
+	}
 
-	idxGen := mkIdxGen(dict, words, unique)
+	if word == "" {
+		app.metrics.IncSkippedWords()
+		return nil
+	}
 
-	// Iterate over different combinations of the best words to find the best pass
-	for idxGen.Next() {
-		idx := idxGen.Combination(nil)
+	app.vocabulary[word] = struct{}{}
 
-		word0 := words[0][idx[0]]
-		word1 := words[1][idx[1]]
-		word2 := words[2][idx[2]]
-		word3 := words[3][idx[3]]
+	dist, err := calcInternalDistance(word, app.calc)
+	if err != nil {
+		return err
+	}
 
-		dist01, err := calcWordDistance(word0.Data, word1.Data, calc)
-		if err != nil {
-			return nil, err
-		}
+	if app.words.add(wItem{Data: word, Dist: dist}, app.config.Pass.classLimit()) {
+		app.metrics.IncFilteredWords()
+	}
 
-		dist12, err := calcWordDistance(word1.Data, word2.Data, calc)
-		if err != nil {
-			return nil, err
-		}
+	return nil
+}
 
-		dist23, err := calcWordDistance(word2.Data, word3.Data, calc)
-		if err != nil {
-			return nil, err
-		}
+// prepareWord lowercases the word and applies the unknown key policy.
+// It returns the empty string if the word must be skipped.
+func (app *App) prepareWord(rawWord string) (string, error) {
+	word := strings.ToLower(rawWord)
 
-		dist := dist01 + dist12 + dist23 + word0.Dist + word1.Dist + word2.Dist + word3.Dist
+	if isMapped(word, app.calc) {
+		return word, nil
+	}
 
-		if dist < bestDist {
-			bestDist = dist
-			password = &wItem{
-				Data: fmt.Sprintf("%s %s %s %s", word0.Data, word1.Data, word2.Data, word3.Data),
-				Dist: dist,
-			}
+	switch app.config.UnknownKeys {
+	case FailUnknown:
+		return "", pkgerr.Wrapf(ErrUnmappedWord, "'%s'", rawWord)
+	case TransliterateUnknown:
+		if transliterated, ok := transliterate(word, app.calc); ok {
+			return transliterated, nil
 		}
+	case SkipUnknown:
 	}
 
-	return password, nil
+	log.WithField("word", rawWord).Debug("Skip word with unknown characters")
+
+	return "", nil
 }
 
-func mkIdxGen(dict []int, words *[passWords][]wItem, unique bool) *combin.Generator[int] {
-	return combin.NewGenerator(dict, passWords, func(idx []int) bool {
-		if len(idx) != len(words) {
-			panic("bad indexes")
-		}
+// getBestPass finds the cheapest passes among all the words, ranked from the cheapest one.
+// The costs are measured again with the chosen words, for the sequence cost models they may
+// differ from the class estimates. The composition rules are applied to the passes found.
+// When the context is done, the passes found so far are returned and reported partial.
+func getBestPass(
+	ctx context.Context, words wordClasses, calc DistanceCalculator, terminals *Terminals, policy *PassPolicy,
+	compose *ComposePolicy,
+) ([]Pass, bool, error) {
+	solver, err := newPassSolver(words, calc, terminals, compose.window(policy, terminals, calc))
+	if err != nil {
+		return nil, false, err
+	}
+
+	best := solver.solve(ctx)
+	if len(best) == 0 && solver.interrupted {
+		return nil, false, pkgerr.Wrap(ctx.Err(), "no pass found before the search is interrupted")
+	}
+
+	if len(best) == 0 {
+		return nil, false, pkgerr.Wrapf(ErrNoPass, "%d words of %d-%d characters",
+			policy.Words, policy.MinLength, policy.MaxLength)
+	}
 
-		keys := make(map[int]bool)
+	passes := make([]Pass, 0, len(best))
 
-		for i := 0; i < len(idx); i++ {
-			if idx[i] >= len(words[0]) {
-				return false
-			}
+	for _, scored := range best {
+		pass, err := calcPass(scored.words, calc, terminals)
+		if err != nil {
+			return nil, false, err
+		}
 
-			keys[idx[i]] = true
+		if err := composePass(pass, calc, terminals, compose, policy); err != nil {
+			return nil, false, err
 		}
 
-		if unique && len(keys) != len(idx) {
-			return false
+		passes = append(passes, *pass)
+	}
+
+	// The ties are in the alphabet order, so the results do not depend on the search order
+	sort.Slice(passes, func(i, j int) bool {
+		if passes[i].Cost != passes[j].Cost {
+			return passes[i].Cost < passes[j].Cost
 		}
 
-		return true
+		return passes[i].Text < passes[j].Text
 	})
+
+	return passes, solver.interrupted, nil
 }
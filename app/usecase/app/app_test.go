@@ -0,0 +1,199 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	pkgerr "github.com/pkg/errors"
+	mockApp "morphbits.io/app/usecase/app/mock"
+	"morphbits.io/app/usecase/keyboard"
+)
+
+func Test_prepareWord(t *testing.T) {
+	t.Parallel()
+
+	testData := []struct {
+		Policy   UnknownKeyPolicy
+		Word     string
+		Expected string
+		Err      error
+	}{
+		{SkipUnknown, "Word", "word", nil},
+		{SkipUnknown, "don't", "", nil},
+		{FailUnknown, "word", "word", nil},
+		{FailUnknown, "café", "", ErrUnmappedWord},
+		{TransliterateUnknown, "Café", "cafe", nil},
+		{TransliterateUnknown, "don't", "dont", nil},
+		{TransliterateUnknown, "straße", "strasse", nil},
+		{TransliterateUnknown, "слово", "", nil},
+	}
+
+	for _, testCase := range testData {
+		ctrl := gomock.NewController(t)
+
+		calc := mockApp.NewMockDistanceCalculator(ctrl)
+		calc.EXPECT().IsMapped(gomock.Any()).AnyTimes().DoAndReturn(func(a rune) bool {
+			return a >= 'a' && a <= 'z'
+		})
+
+		app := New(nil, nil, calc, Config{UnknownKeys: testCase.Policy})
+
+		word, err := app.prepareWord(testCase.Word)
+		if !errors.Is(err, testCase.Err) {
+			t.Errorf("%s '%s': expected error '%v', got '%v'", testCase.Policy, testCase.Word, testCase.Err, err)
+		}
+
+		if word != testCase.Expected {
+			t.Errorf("%s '%s': expected '%s', got '%s'", testCase.Policy, testCase.Word, testCase.Expected, word)
+		}
+
+		ctrl.Finish()
+	}
+}
+
+func Test_handleWordSkipped(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	calc := mockApp.NewMockDistanceCalculator(ctrl)
+	calc.EXPECT().IsMapped(gomock.Any()).AnyTimes().DoAndReturn(func(a rune) bool {
+		return strings.ContainsRune("abc", a)
+	})
+
+	metrics := mockApp.NewMockMetrics(ctrl)
+	metrics.EXPECT().IncWords().Times(2)
+	metrics.EXPECT().IncSkippedWords().Times(2)
+
+	app := New(metrics, nil, calc, DefaultConfig())
+
+	for _, word := range []string{"abd", ""} {
+		if err := app.handleWord(word); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(app.words) != 0 {
+		t.Errorf("Expected no words, got %v", app.words)
+	}
+}
+
+func Test_Run(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	kbd, err := keyboard.NewQWERTY()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dictReader := mockApp.NewMockDictReader(ctrl)
+	read := func(_ context.Context, handler func(string) error) error {
+		for _, word := range []string{"asdfg", "hjkl", "qwert", "poiuy", "zxcvb"} {
+			if err := handler(word); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	dictReader.EXPECT().Run(gomock.Any(), gomock.Any()).DoAndReturn(read)
+
+	metrics := mockApp.NewMockMetrics(ctrl)
+	metrics.EXPECT().IncWords().AnyTimes()
+	metrics.EXPECT().IncFilteredWords().AnyTimes()
+
+	config := DefaultConfig()
+	config.Terminals.Separator = Separator{Kind: HyphenSeparator, Text: ""}
+	config.Pass.Results = 2
+
+	result, err := New(metrics, dictReader, kbd, config).Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Layout != "qwerty" || result.Config.Pass.Words != 4 || len(result.Passes) != 2 {
+		t.Fatalf("Expected two passes on qwerty; got: %+v", result)
+	}
+
+	pass := result.Passes[0]
+	if pass.Text != "qwert-hjkl-poiuy-asdfg" || pass.Cost != 53 || pass.Length != 22 {
+		t.Errorf("Expected 'qwert-hjkl-poiuy-asdfg' of 53; got: %+v", pass)
+	}
+
+	// The cheapest pass is reproducible, 5 words give 5*4*3*2 sequences
+	if result.Entropy.Selection != 0 || math.Abs(result.Entropy.Dictionary-math.Log2(120)) > 1e-9 || result.Entropy.Weak {
+		t.Errorf("Expected 0 and log2(120) bits; got: %+v", result.Entropy)
+	}
+}
+
+func Test_RunInterrupted(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	kbd, err := keyboard.NewQWERTY()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The deadline is hit after 5 words, the rest is not read
+	dictReader := mockApp.NewMockDictReader(ctrl)
+	read := func(ctx context.Context, handler func(string) error) error {
+		for _, word := range []string{"asdfg", "hjkl", "qwert", "poiuy", "zxcvb", "ghjkl"} {
+			if err := ctx.Err(); err != nil {
+				return pkgerr.Wrap(err, "interrupted")
+			}
+
+			if err := handler(word); err != nil {
+				return err
+			}
+
+			if word == "zxcvb" {
+				cancel()
+			}
+		}
+
+		return nil
+	}
+
+	dictReader.EXPECT().Run(gomock.Any(), gomock.Any()).DoAndReturn(read)
+
+	metrics := mockApp.NewMockMetrics(ctrl)
+	metrics.EXPECT().IncWords().AnyTimes()
+	metrics.EXPECT().IncFilteredWords().AnyTimes()
+
+	config := DefaultConfig()
+	config.Terminals.Separator = Separator{Kind: HyphenSeparator, Text: ""}
+
+	app := New(metrics, dictReader, kbd, config)
+
+	result, err := app.Run(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !result.Partial || len(result.Passes) != config.Pass.Results || len(app.vocabulary) != 5 {
+		t.Errorf("Expected the passes of the words read; got: %+v", result)
+	}
+
+	// Other reading errors fail the run
+	readErr := errors.New("no disk")
+	dictReader.EXPECT().Run(gomock.Any(), gomock.Any()).Return(readErr)
+
+	if _, err := New(metrics, dictReader, kbd, config).Run(context.Background()); !errors.Is(err, readErr) {
+		t.Errorf("Expected the read error; got: %v", err)
+	}
+}
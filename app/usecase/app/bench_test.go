@@ -0,0 +1,90 @@
+package app
+
+import (
+	"bufio"
+	"os"
+	"testing"
+
+	"morphbits.io/app/usecase/keyboard"
+)
+
+const benchDict = "../../../data/corncob_lowercase.txt"
+
+func loadBenchWords(b *testing.B, calc DistanceCalculator) []string {
+	b.Helper()
+
+	f, err := os.Open(benchDict)
+	if err != nil {
+		b.Skipf("no dictionary: %v", err)
+	}
+
+	defer f.Close()
+
+	var words []string
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if word := scanner.Text(); word != "" && isMapped(word, calc) {
+			words = append(words, word)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		b.Fatal(err)
+	}
+
+	return words
+}
+
+func benchCalculators(b *testing.B) map[string]DistanceCalculator {
+	b.Helper()
+
+	kbd, err := keyboard.NewQWERTY(keyboard.WithMetric(keyboard.Euclidean), keyboard.WithResolution(100))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	matrix, err := keyboard.NewKeyboardMatrix(kbd)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	return map[string]DistanceCalculator{
+		"keyboard": kbd,
+		"matrix":   matrix,
+	}
+}
+
+func Benchmark_calcInternalDistance(b *testing.B) {
+	for name, calc := range benchCalculators(b) {
+		calc := calc
+		words := loadBenchWords(b, calc)
+
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for _, word := range words {
+					if _, err := calcInternalDistance(word, calc); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
+
+func Benchmark_calcWordDistance(b *testing.B) {
+	for name, calc := range benchCalculators(b) {
+		calc := calc
+		words := loadBenchWords(b, calc)
+
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for j := 1; j < len(words); j++ {
+					if _, err := calcWordDistance(words[j-1], words[j], calc); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
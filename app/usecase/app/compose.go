@@ -0,0 +1,321 @@
+package app
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	pkgerr "github.com/pkg/errors"
+)
+
+// composeState is the composition search state at a gap of the pass text: the characters of every
+// kind typed so far, up to the policy counts, the number of the inserted ones and the last one typed.
+type composeState struct {
+	upper, digits, symbols int
+	inserted               int
+	last                   rune // Zero before the first character
+}
+
+// composeStep is the cheapest way to the state: the character typed last and the previous state.
+type composeStep struct {
+	cost  int
+	char  rune
+	from  composeState
+	layer int // Of the previous state, -1 for the initial one
+}
+
+// composer places the capitals, digits and symbols of the policy into the pass text with the least
+// added cost. The layer i of the search is the gap before the i-th character of the text, the
+// characters are inserted within the layer and typed, as they are or capitalized, between the layers.
+// The cost is pairwise, for the sequence cost models it is measured again with the result.
+type composer struct {
+	calc      DistanceCalculator
+	terminals *Terminals
+	policy    *ComposePolicy
+	inserts   []rune // The digits and symbols on the layout
+}
+
+func newComposer(calc DistanceCalculator, terminals *Terminals, policy *ComposePolicy) *composer {
+	c := &composer{
+		calc:      calc,
+		terminals: terminals,
+		policy:    policy,
+		inserts:   nil,
+	}
+
+	for _, char := range pinDigits + policy.Charset {
+		if calc.IsMapped(char) && !strings.ContainsRune(string(c.inserts), char) {
+			c.inserts = append(c.inserts, char)
+		}
+	}
+
+	return c
+}
+
+// required reports whether the policy demands any characters.
+func (p *ComposePolicy) required() bool {
+	return p.Upper+p.Digits+p.Symbols > 0
+}
+
+// inserts is the number of the characters to insert into the pass, the separators may have
+// some of the digits and symbols already.
+func (p *ComposePolicy) inserts(words int, terminals *Terminals, calc DistanceCalculator) int {
+	digits, symbols := p.Digits, p.Symbols
+
+	if choices := terminals.Separator.choices(calc); len(choices) != 0 {
+		for _, char := range choices[0] {
+			if isDigit(char) {
+				digits -= words - 1
+			}
+
+			if strings.ContainsRune(p.Charset, char) {
+				symbols -= words - 1
+			}
+		}
+	}
+
+	inserts := 0
+
+	for _, count := range []int{digits, symbols} {
+		if count > 0 {
+			inserts += count
+		}
+	}
+
+	return inserts
+}
+
+// window is the pass policy with the room for the inserted characters in the length window.
+func (p *ComposePolicy) window(policy *PassPolicy, terminals *Terminals, calc DistanceCalculator) *PassPolicy {
+	window := *policy
+
+	inserts := p.inserts(policy.Words, terminals, calc)
+	window.MinLength -= inserts
+	window.MaxLength -= inserts
+
+	if window.MinLength < 0 {
+		window.MinLength = 0
+	}
+
+	return &window
+}
+
+// compose returns the cheapest text with the policy characters within the length window.
+func (c *composer) compose(text string, minLength, maxLength int) (string, error) {
+	chars := []rune(text)
+
+	limit := maxLength - len(chars)
+	if limit > c.policy.Digits+c.policy.Symbols {
+		limit = c.policy.Digits + c.policy.Symbols
+	}
+
+	layers := make([]map[composeState]composeStep, len(chars)+1)
+	layers[0] = map[composeState]composeStep{
+		{upper: 0, digits: 0, symbols: 0, inserted: 0, last: 0}: {cost: 0, char: 0, from: composeState{}, layer: -1},
+	}
+
+	for i := 0; i <= len(chars); i++ {
+		if err := c.insert(layers, i, limit); err != nil {
+			return "", err
+		}
+
+		if i == len(chars) {
+			break
+		}
+
+		layers[i+1] = make(map[composeState]composeStep)
+
+		for _, state := range sortedStates(layers[i]) {
+			for _, char := range c.variants(chars[i], state) {
+				if err := c.relax(layers, i, state, i+1, char, false); err != nil {
+					return "", err
+				}
+			}
+		}
+	}
+
+	final, found, err := c.final(layers[len(chars)], len(chars), minLength)
+	if err != nil {
+		return "", err
+	}
+
+	if !found {
+		return "", pkgerr.Wrapf(ErrNoCompose, "%d capitals, %d digits and %d symbols in '%s' of at most %d characters",
+			c.policy.Upper, c.policy.Digits, c.policy.Symbols, text, maxLength)
+	}
+
+	composed := make([]rune, 0, len(chars)+limit)
+
+	for state, layer := final, len(chars); layer >= 0; {
+		step := layers[layer][state]
+		if step.layer < 0 {
+			break
+		}
+
+		composed = append(composed, step.char)
+		state, layer = step.from, step.layer
+	}
+
+	for i, j := 0, len(composed)-1; i < j; i, j = i+1, j-1 {
+		composed[i], composed[j] = composed[j], composed[i]
+	}
+
+	return string(composed), nil
+}
+
+// insert tries the digits and the symbols at the gap, the states with fewer characters inserted first.
+func (c *composer) insert(layers []map[composeState]composeStep, i, limit int) error {
+	for inserted := 0; inserted < limit; inserted++ {
+		for _, state := range sortedStates(layers[i]) {
+			if state.inserted != inserted {
+				continue
+			}
+
+			for _, char := range c.inserts {
+				if !c.needed(state, char) {
+					continue
+				}
+
+				if err := c.relax(layers, i, state, i, char, true); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// relax types the character after the state of the layer, the state it leads to keeps the cheapest way.
+func (c *composer) relax(
+	layers []map[composeState]composeStep, layer int, state composeState, nextLayer int, char rune, inserted bool,
+) error {
+	cost, err := c.cost(state.last, char)
+	if err != nil {
+		return err
+	}
+
+	cost += layers[layer][state].cost
+	next := c.next(state, char, inserted)
+
+	if step, ok := layers[nextLayer][next]; ok && step.cost <= cost {
+		return nil
+	}
+
+	layers[nextLayer][next] = composeStep{cost: cost, char: char, from: state, layer: layer}
+
+	return nil
+}
+
+// needed reports whether the inserted character counts for the policy.
+func (c *composer) needed(state composeState, char rune) bool {
+	if isDigit(char) && state.digits < c.policy.Digits {
+		return true
+	}
+
+	return strings.ContainsRune(c.policy.Charset, char) && state.symbols < c.policy.Symbols
+}
+
+// variants are the characters to type for the text character: itself or the capital.
+func (c *composer) variants(char rune, state composeState) []rune {
+	variants := []rune{char}
+
+	if state.upper < c.policy.Upper && unicode.IsLower(char) {
+		if upper := unicode.ToUpper(char); upper != char && c.calc.IsMapped(upper) {
+			variants = append(variants, upper)
+		}
+	}
+
+	return variants
+}
+
+// next is the state after typing the character.
+func (c *composer) next(state composeState, char rune, inserted bool) composeState {
+	if unicode.IsUpper(char) && state.upper < c.policy.Upper {
+		state.upper++
+	}
+
+	if isDigit(char) && state.digits < c.policy.Digits {
+		state.digits++
+	}
+
+	if strings.ContainsRune(c.policy.Charset, char) && state.symbols < c.policy.Symbols {
+		state.symbols++
+	}
+
+	if inserted {
+		state.inserted++
+	}
+
+	state.last = char
+
+	return state
+}
+
+// final is the cheapest state at the end of the text with all the policy characters and the end key.
+func (c *composer) final(layer map[composeState]composeStep, length, minLength int) (composeState, bool, error) {
+	best, found, bestCost := composeState{}, false, 0
+
+	for _, state := range sortedStates(layer) {
+		if state.upper < c.policy.Upper || state.digits < c.policy.Digits || state.symbols < c.policy.Symbols ||
+			length+state.inserted < minLength {
+			continue
+		}
+
+		end, err := calcEndDistances([]wItem{{Data: string(state.last), Dist: 0}}, c.terminals.End, c.calc)
+		if err != nil {
+			return best, false, err
+		}
+
+		if cost := layer[state].cost + end[0]; !found || cost < bestCost {
+			best, found, bestCost = state, true, cost
+		}
+	}
+
+	return best, found, nil
+}
+
+// cost is the travel from the last character to the next one, from the start key for the first one.
+func (c *composer) cost(last, char rune) (int, error) {
+	if last != 0 {
+		return c.calc.GetDistance(last, char)
+	}
+
+	start, err := calcStartDistances([]wItem{{Data: string(char), Dist: 0}}, c.terminals.Start, c.calc)
+	if err != nil {
+		return 0, err
+	}
+
+	return start[0], nil
+}
+
+// sortedStates returns the states in the stable order, so the ties are broken the same way.
+func sortedStates(layer map[composeState]composeStep) []composeState {
+	states := make([]composeState, 0, len(layer))
+	for state := range layer {
+		states = append(states, state)
+	}
+
+	sort.Slice(states, func(i, j int) bool {
+		a, b := states[i], states[j]
+
+		switch {
+		case a.inserted != b.inserted:
+			return a.inserted < b.inserted
+		case a.upper != b.upper:
+			return a.upper < b.upper
+		case a.digits != b.digits:
+			return a.digits < b.digits
+		case a.symbols != b.symbols:
+			return a.symbols < b.symbols
+		}
+
+		return a.last < b.last
+	})
+
+	return states
+}
+
+func isDigit(char rune) bool {
+	return char >= '0' && char <= '9'
+}
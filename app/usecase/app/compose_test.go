@@ -0,0 +1,255 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"unicode"
+
+	"morphbits.io/app/usecase/keyboard"
+)
+
+// bruteForceCompose tries every capital and every digit and symbol at every gap and returns the cheapest cost.
+func bruteForceCompose(t *testing.T, text string, calc DistanceCalculator, terminals *Terminals, symbols string) int {
+	t.Helper()
+
+	best := -1
+
+	insert := func(chars []rune, i int, char rune) []rune {
+		return append(append(append([]rune(nil), chars[:i]...), char), chars[i:]...)
+	}
+
+	chars := []rune(text)
+
+	for c := 0; c < len(chars); c++ {
+		if !unicode.IsLower(chars[c]) {
+			continue
+		}
+
+		capital := append([]rune(nil), chars...)
+		capital[c] = unicode.ToUpper(capital[c])
+
+		for d := 0; d <= len(capital); d++ {
+			for _, digit := range pinDigits {
+				withDigit := insert(capital, d, digit)
+
+				for s := 0; s <= len(withDigit); s++ {
+					for _, symbol := range symbols {
+						if !calc.IsMapped(symbol) {
+							continue
+						}
+
+						cost, err := calcTextDistance(string(insert(withDigit, s, symbol)), terminals, calc)
+						if err != nil {
+							t.Fatal(err)
+						}
+
+						if best < 0 || cost < best {
+							best = cost
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return best
+}
+
+func Test_composerExact(t *testing.T) {
+	t.Parallel()
+
+	kbd, err := keyboard.NewQWERTY()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	policy := ComposePolicy{Upper: 1, Digits: 1, Symbols: 1, Charset: "!-=[]/"}
+
+	for _, terminals := range []Terminals{
+		{Start: 0, End: 0, Separator: noSeparator},
+		{Start: 'g', End: '\n', Separator: noSeparator},
+	} {
+		for _, text := range []string{"as df", "plop", "zap-mix"} {
+			composed, err := newComposer(kbd, &terminals, &policy).compose(text, 0, 20)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			cost, err := calcTextDistance(composed, &terminals, kbd)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if expected := bruteForceCompose(t, text, kbd, &terminals, policy.Charset); cost != expected {
+				t.Errorf("Expected '%s' composed at the cost %d; got: '%s' of %d", text, expected, composed, cost)
+			}
+
+			if !strings.ContainsAny(composed, "ASDFPLOZMIX") || !strings.ContainsAny(composed, pinDigits) {
+				t.Errorf("Expected a capital and a digit in '%s'", composed)
+			}
+		}
+	}
+}
+
+func Test_composerLeadingCapital(t *testing.T) {
+	t.Parallel()
+
+	kbd, err := keyboard.NewQWERTY()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	terminals := Terminals{Start: 0, End: 0, Separator: noSeparator}
+
+	// 'D' costs 4 over 'd' wherever it is: Shift (-1, 3) -> d (2, 2) at the start,
+	// a (0, 2) -> Shift -> d instead of a -> d after 'a'
+	testData := []struct {
+		Text, Capital string
+	}{
+		{"deed", "Deed"},
+		{"adze", "aDze"},
+	}
+
+	for _, testCase := range testData {
+		base, err := calcTextDistance(testCase.Text, &terminals, kbd)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		capital, err := calcTextDistance(testCase.Capital, &terminals, kbd)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if capital-base != 4 {
+			t.Errorf("Expected '%s' to cost 4 over '%s'; got: %d", testCase.Capital, testCase.Text, capital-base)
+		}
+	}
+
+	// The leading capital is not free, the composer weighs it against the other positions
+	policy := ComposePolicy{Upper: 1, Digits: 0, Symbols: 0, Charset: ""}
+
+	for _, text := range []string{"deed", "hmm", "hymn", "breeds"} {
+		composed, err := newComposer(kbd, &terminals, &policy).compose(text, 0, len(text))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		base, err := calcTextDistance(text, &terminals, kbd)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cost, err := calcTextDistance(composed, &terminals, kbd)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if expected := bruteForceCapital(t, text, kbd, &terminals); cost != expected || cost == base {
+			t.Errorf("Expected '%s' composed at the cost %d over %d; got: '%s' of %d", text, expected, base, composed, cost)
+		}
+	}
+}
+
+// bruteForceCapital tries the capital at every position and returns the cheapest cost.
+func bruteForceCapital(t *testing.T, text string, calc DistanceCalculator, terminals *Terminals) int {
+	t.Helper()
+
+	best := -1
+
+	for i := range text {
+		chars := []rune(text)
+		chars[i] = unicode.ToUpper(chars[i])
+
+		cost, err := calcTextDistance(string(chars), terminals, calc)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if best < 0 || cost < best {
+			best = cost
+		}
+	}
+
+	return best
+}
+
+func Test_composerWindow(t *testing.T) {
+	t.Parallel()
+
+	kbd, err := keyboard.NewQWERTY()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	terminals := Terminals{Start: 0, End: 0, Separator: hyphenSeparator}
+	policy := ComposePolicy{Upper: 0, Digits: 1, Symbols: 1, Charset: "-"}
+
+	// The hyphen is there already, the digit does not fit
+	if _, err := newComposer(kbd, &terminals, &policy).compose("as-df", 0, 5); !errors.Is(err, ErrNoCompose) {
+		t.Errorf("Expected no composition error; got: %v", err)
+	}
+
+	composed, err := newComposer(kbd, &terminals, &policy).compose("as-df", 0, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(composed) != 6 || strings.Count(composed, "-") != 1 {
+		t.Errorf("Expected one digit inserted into 'as-df'; got: '%s'", composed)
+	}
+
+	if inserts := policy.inserts(4, &terminals, kbd); inserts != 1 {
+		t.Errorf("Expected 1 insert with the hyphens; got: %d", inserts)
+	}
+
+	digits := Terminals{Start: 0, End: 0, Separator: Separator{Kind: DigitSeparator, Text: ""}}
+	if inserts := policy.inserts(4, &digits, kbd); inserts != 1 {
+		t.Errorf("Expected 1 insert with the digits; got: %d", inserts)
+	}
+}
+
+func Test_getBestPassCompose(t *testing.T) {
+	t.Parallel()
+
+	kbd, err := keyboard.NewQWERTY()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	policy := DefaultConfig().Pass
+	compose := ComposePolicy{Upper: 2, Digits: 1, Symbols: 1, Charset: defaultSymbols}
+	terminals := Terminals{Start: 0, End: 0, Separator: Separator{Kind: SpaceSeparator, Text: ""}}
+
+	classes, _ := newTestClasses(t, kbd, policy.classLimit(), "asdfg", "hjkl", "qwert", "poiuy", "zxcvb", "ghjkl")
+
+	passes, _, err := getBestPass(context.Background(), classes, kbd, &terminals, &policy, &compose)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(passes) == 0 {
+		t.Fatal("Expected the passes")
+	}
+
+	for _, pass := range passes {
+		if pass.Length < policy.MinLength || pass.Length > policy.MaxLength || pass.Length != len(pass.Text) {
+			t.Errorf("Expected the length within the window: %+v", pass)
+		}
+
+		if pass.Base != strings.ToLower(pass.Base) || pass.Text == pass.Base || pass.Extra <= 0 {
+			t.Errorf("Expected the composition rules applied to the base pass: %+v", pass)
+		}
+
+		base, err := calcTextDistance(pass.Base, &terminals, kbd)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if base+pass.Extra != pass.Cost {
+			t.Errorf("Expected the cost %d + %d; got: %d", base, pass.Extra, pass.Cost)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package app
+
+import (
+	"errors"
+	"math"
+	"strings"
+
+	pkgerr "github.com/pkg/errors"
+	"morphbits.io/app/usecase/utils"
+)
+
+// UnknownKeyPolicy defines what to do with the dictionary words which have
+// characters missing on the keyboard layout.
+type UnknownKeyPolicy int
+
+const (
+	SkipUnknown          UnknownKeyPolicy = iota // Ignore such words
+	FailUnknown                                  // Abort with ErrUnmappedWord
+	TransliterateUnknown                         // Replace accented letters, drop punctuation, skip the rest
+)
+
+const (
+	defaultPINLength   = 4
+	defaultPINDistinct = 3
+
+	defaultPassWords     = 4
+	defaultPassMinLength = 20
+	defaultPassMaxLength = 24
+	minPassWords         = 2
+	maxPassWords         = 8
+	maxPassLength        = 256 // Bounds the search state
+	defaultPassResults   = 5
+	maxPassResults       = 1000
+	maxComposeChars      = 4 // Of every kind, bounds the composition search
+
+	// defaultSymbols are the ASCII punctuation characters
+	defaultSymbols = "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"
+)
+
+var (
+	ErrUnknownPolicy = errors.New("unknown policy")
+	ErrUnknownMode   = errors.New("unknown mode")
+	ErrBadPINPolicy  = errors.New("bad PIN policy")
+	ErrBadPassPolicy = errors.New("bad passphrase policy")
+	ErrUnmappedWord  = errors.New("word has characters which are not on the layout")
+	ErrUnmappedKey   = errors.New("terminal key is not on the layout")
+	ErrNoPass        = errors.New("no pass of the allowed length")
+	ErrPoolTooLarge  = errors.New("too many passes within the budget")
+	ErrBadStrength   = errors.New("bad target strength")
+	ErrWeakPass      = errors.New("selection entropy is below the target")
+	ErrBadCompose    = errors.New("bad composition policy")
+	ErrNoCompose     = errors.New("composition rules can't be met")
+)
+
+var unknownKeyPolicyNames = map[UnknownKeyPolicy]string{
+	SkipUnknown:          "skip",
+	FailUnknown:          "fail",
+	TransliterateUnknown: "transliterate",
+}
+
+// ParseUnknownKeyPolicy returns the policy by its name: skip, fail or transliterate.
+func ParseUnknownKeyPolicy(name string) (UnknownKeyPolicy, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	for policy, policyName := range unknownKeyPolicyNames {
+		if policyName == name {
+			return policy, nil
+		}
+	}
+
+	return SkipUnknown, pkgerr.Wrapf(ErrUnknownPolicy, "'%s'", name)
+}
+
+func (p UnknownKeyPolicy) String() string {
+	if name, ok := unknownKeyPolicyNames[p]; ok {
+		return name
+	}
+
+	return "unknown"
+}
+
+// Mode is what the application generates.
+type Mode int
+
+const (
+	PassphraseMode Mode = iota // Words from the dictionary
+	PINMode                    // Digits only
+)
+
+var modeNames = map[Mode]string{
+	PassphraseMode: "passphrase",
+	PINMode:        "pin",
+}
+
+// ParseMode returns the mode by its name: passphrase or pin.
+func ParseMode(name string) (Mode, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	for mode, modeName := range modeNames {
+		if modeName == name {
+			return mode, nil
+		}
+	}
+
+	return PassphraseMode, pkgerr.Wrapf(ErrUnknownMode, "'%s'", name)
+}
+
+func (m Mode) String() string {
+	if name, ok := modeNames[m]; ok {
+		return name
+	}
+
+	return "unknown"
+}
+
+// PINPolicy defines the PIN length and the guardrails against the trivially guessable PINs.
+type PINPolicy struct {
+	Length         int  // Number of digits
+	MinDistinct    int  // Minimum number of different digits
+	AllowRepeats   bool // Allow the same digit twice in a row, e.g. 1123
+	AllowSequences bool // Allow the digits with the constant step, e.g. 1234, 9753 or 8901
+	AllowLines     bool // Allow all the keys on a straight line, e.g. 2580, needs the KeyLocator
+}
+
+// PassPolicy defines the passphrase words.
+type PassPolicy struct {
+	Words     int  // Number of words
+	MinLength int  // Minimum number of characters, the separators included
+	MaxLength int  // Maximum number of characters
+	Unique    bool // Use every word once
+	Results   int  // Number of the cheapest passphrases to find
+	Random    bool // Pick one pass at random instead of the cheapest ones
+	Budget    int  // Extra cost over the cheapest pass allowed for the random pick
+}
+
+// classLimit is the number of the cheapest words to keep for each first and last characters and length.
+// A pass with the worse word is beaten by at least Results passes with the cheaper unused words of its class.
+// The random pick keeps all the words, the ones out of the budget are dropped after the dictionary is read.
+func (p *PassPolicy) classLimit() int {
+	if p.Random {
+		return utils.MaxInt()
+	}
+
+	return p.Words + p.Results - 1
+}
+
+// StrengthPolicy is the target strength of the result.
+type StrengthPolicy struct {
+	MinBits float64 // Minimum selection entropy, zero for no check
+	Fail    bool    // Fail the run with ErrWeakPass instead of the warning
+}
+
+// ComposePolicy is the characters the target systems demand in the passphrase.
+type ComposePolicy struct {
+	Upper   int    // Minimum number of capital letters
+	Digits  int    // Minimum number of digits
+	Symbols int    // Minimum number of symbols
+	Charset string // Characters counted as the symbols
+}
+
+// Terminals are the keys typed around the passphrase words, zero if not typed.
+type Terminals struct {
+	Start     rune      // Key the finger rests on before typing
+	End       rune      // Key pressed after the passphrase, e.g. Enter
+	Separator Separator // Typed between the words
+}
+
+// Config is the runtime configuration of the application.
+type Config struct {
+	UnknownKeys UnknownKeyPolicy
+	Mode        Mode
+	PIN         PINPolicy
+	Pass        PassPolicy
+	Strength    StrengthPolicy
+	Compose     ComposePolicy
+	Terminals   Terminals
+}
+
+func DefaultConfig() Config {
+	return Config{
+		UnknownKeys: SkipUnknown,
+		Mode:        PassphraseMode,
+		PIN: PINPolicy{
+			Length:         defaultPINLength,
+			MinDistinct:    defaultPINDistinct,
+			AllowRepeats:   false,
+			AllowSequences: false,
+			AllowLines:     false,
+		},
+		Pass: PassPolicy{
+			Words:     defaultPassWords,
+			MinLength: defaultPassMinLength,
+			MaxLength: defaultPassMaxLength,
+			Unique:    true,
+			Results:   defaultPassResults,
+			Random:    false,
+			Budget:    0,
+		},
+		Strength: StrengthPolicy{
+			MinBits: 0,
+			Fail:    false,
+		},
+		Compose: ComposePolicy{
+			Upper:   0,
+			Digits:  0,
+			Symbols: 0,
+			Charset: defaultSymbols,
+		},
+		Terminals: Terminals{
+			Start:     0,
+			End:       0,
+			Separator: Separator{Kind: SpaceSeparator, Text: ""},
+		},
+	}
+}
+
+// Validate checks the target strength and the policy of the mode.
+func (c *Config) Validate() error {
+	if c.Strength.MinBits < 0 || math.IsNaN(c.Strength.MinBits) {
+		return pkgerr.Wrapf(ErrBadStrength, "%v bits", c.Strength.MinBits)
+	}
+
+	switch c.Mode {
+	case PassphraseMode:
+		if err := validatePassPolicy(&c.Pass); err != nil {
+			return err
+		}
+
+		return validateComposePolicy(&c.Compose)
+	case PINMode:
+		return validatePINPolicy(&c.PIN)
+	}
+
+	return pkgerr.Wrapf(ErrUnknownMode, "%d", c.Mode)
+}
+
+func validatePassPolicy(policy *PassPolicy) error {
+	if policy.Words < minPassWords || policy.Words > maxPassWords {
+		return pkgerr.Wrapf(ErrBadPassPolicy, "%d words is out of [%d, %d]", policy.Words, minPassWords, maxPassWords)
+	}
+
+	if policy.MinLength < 0 || policy.MinLength > policy.MaxLength || policy.MaxLength > maxPassLength {
+		return pkgerr.Wrapf(ErrBadPassPolicy, "length [%d, %d] is out of [0, %d]",
+			policy.MinLength, policy.MaxLength, maxPassLength)
+	}
+
+	if policy.Results < 1 || policy.Results > maxPassResults {
+		return pkgerr.Wrapf(ErrBadPassPolicy, "%d results is out of [1, %d]", policy.Results, maxPassResults)
+	}
+
+	if policy.Budget < 0 {
+		return pkgerr.Wrapf(ErrBadPassPolicy, "negative budget %d", policy.Budget)
+	}
+
+	return nil
+}
+
+func validateComposePolicy(policy *ComposePolicy) error {
+	for _, count := range []int{policy.Upper, policy.Digits, policy.Symbols} {
+		if count < 0 || count > maxComposeChars {
+			return pkgerr.Wrapf(ErrBadCompose, "%d characters of a kind is out of [0, %d]", count, maxComposeChars)
+		}
+	}
+
+	if policy.Symbols > 0 && policy.Charset == "" {
+		return pkgerr.Wrap(ErrBadCompose, "no symbols")
+	}
+
+	return nil
+}
+
+// validate checks the terminal keys and the separator are on the layout.
+func (t *Terminals) validate(calc DistanceCalculator) error {
+	for _, key := range []rune{t.Start, t.End} {
+		if key != 0 && !calc.IsMapped(key) {
+			return pkgerr.Wrapf(ErrUnmappedKey, "%q", key)
+		}
+	}
+
+	if len(t.Separator.choices(calc)) == 0 {
+		return pkgerr.Wrapf(ErrUnmappedKey, "separator '%s'", t.Separator)
+	}
+
+	return nil
+}
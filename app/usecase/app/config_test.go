@@ -0,0 +1,40 @@
+package app
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_ConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	testData := []struct {
+		Pass PassPolicy
+		Err  error
+	}{
+		{DefaultConfig().Pass, nil},
+		{PassPolicy{Words: 8, MinLength: 0, MaxLength: 256, Unique: false, Results: 1, Random: false, Budget: 0}, nil},
+		{PassPolicy{Words: 1, MinLength: 20, MaxLength: 24, Unique: true, Results: 1, Random: false, Budget: 0},
+			ErrBadPassPolicy},
+		{PassPolicy{Words: 9, MinLength: 20, MaxLength: 24, Unique: true, Results: 1, Random: false, Budget: 0},
+			ErrBadPassPolicy},
+		{PassPolicy{Words: 4, MinLength: 25, MaxLength: 24, Unique: true, Results: 1, Random: false, Budget: 0},
+			ErrBadPassPolicy},
+		{PassPolicy{Words: 4, MinLength: 20, MaxLength: 1000, Unique: true, Results: 1, Random: false, Budget: 0},
+			ErrBadPassPolicy},
+		{PassPolicy{Words: 4, MinLength: 20, MaxLength: 24, Unique: true, Results: 0, Random: false, Budget: 0},
+			ErrBadPassPolicy},
+		{PassPolicy{Words: 4, MinLength: 20, MaxLength: 24, Unique: true, Results: 1, Random: true, Budget: 3}, nil},
+		{PassPolicy{Words: 4, MinLength: 20, MaxLength: 24, Unique: true, Results: 1, Random: true, Budget: -1},
+			ErrBadPassPolicy},
+	}
+
+	for _, testCase := range testData {
+		config := DefaultConfig()
+		config.Pass = testCase.Pass
+
+		if err := config.Validate(); !errors.Is(err, testCase.Err) {
+			t.Errorf("Expected %v for %+v; got: %v", testCase.Err, testCase.Pass, err)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package app
+
+import (
+	"math"
+
+	pkgerr "github.com/pkg/errors"
+)
+
+// Entropy is the strength of the result in bits.
+type Entropy struct {
+	// Selection is for the attacker who knows the algorithm, the dictionary and the layout.
+	// The cheapest passes are reproduced by running the tool, so only the random pick has it.
+	Selection float64
+	// Dictionary is the naive estimate for the attacker who knows only the dictionary and
+	// the number of words: any words in any order.
+	Dictionary float64
+	// Weak is set when the selection entropy is below the target.
+	Weak bool
+}
+
+// analyzeEntropy estimates the result strength for the config. The vocabulary is the number of the
+// usable dictionary words and the pool is the number of the passes the random one is picked from.
+func analyzeEntropy(config *Config, vocabulary, pool int) Entropy {
+	if config.Mode == PINMode {
+		return Entropy{
+			Selection:  0,
+			Dictionary: float64(config.PIN.Length) * math.Log2(float64(len(pinDigits))),
+			Weak:       false,
+		}
+	}
+
+	entropy := Entropy{
+		Selection:  0,
+		Dictionary: dictionaryEntropy(vocabulary, config.Pass.Words, config.Pass.Unique),
+		Weak:       false,
+	}
+
+	if config.Pass.Random && pool > 0 {
+		entropy.Selection = math.Log2(float64(pool))
+	}
+
+	return entropy
+}
+
+// dictionaryEntropy is log2 of the number of the word sequences, without the repeats if unique.
+func dictionaryEntropy(vocabulary, words int, unique bool) float64 {
+	if vocabulary < words {
+		return 0
+	}
+
+	if !unique {
+		return float64(words) * math.Log2(float64(vocabulary))
+	}
+
+	bits := 0.0
+	for i := 0; i < words; i++ {
+		bits += math.Log2(float64(vocabulary - i))
+	}
+
+	return bits
+}
+
+// check marks the result weak, or fails with ErrWeakPass if the policy says so.
+func (e *Entropy) check(policy *StrengthPolicy) error {
+	if e.Selection >= policy.MinBits {
+		return nil
+	}
+
+	if policy.Fail {
+		return pkgerr.Wrapf(ErrWeakPass, "%.1f bits, %.1f required", e.Selection, policy.MinBits)
+	}
+
+	e.Weak = true
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func Test_analyzeEntropy(t *testing.T) {
+	t.Parallel()
+
+	pin := DefaultConfig()
+	pin.Mode = PINMode
+
+	random := DefaultConfig()
+	random.Pass.Random = true
+
+	repeats := DefaultConfig()
+	repeats.Pass.Unique = false
+	repeats.Pass.Words = 2
+
+	testData := []struct {
+		Config     Config
+		Vocabulary int
+		Pool       int
+		Selection  float64
+		Dictionary float64
+	}{
+		{DefaultConfig(), 4, 0, 0, math.Log2(4 * 3 * 2 * 1)},
+		{DefaultConfig(), 3, 0, 0, 0},
+		{random, 10, 1024, 10, math.Log2(10 * 9 * 8 * 7)},
+		{random, 10, 0, 0, math.Log2(10 * 9 * 8 * 7)},
+		{repeats, 1024, 0, 0, 20},
+		{pin, 0, 0, 0, 4 * math.Log2(10)},
+	}
+
+	for _, testCase := range testData {
+		entropy := analyzeEntropy(&testCase.Config, testCase.Vocabulary, testCase.Pool)
+
+		if math.Abs(entropy.Selection-testCase.Selection) > 1e-9 ||
+			math.Abs(entropy.Dictionary-testCase.Dictionary) > 1e-9 {
+			t.Errorf("Expected %v and %v bits for %d words, pool %d; got: %+v",
+				testCase.Selection, testCase.Dictionary, testCase.Vocabulary, testCase.Pool, entropy)
+		}
+	}
+}
+
+func Test_EntropyCheck(t *testing.T) {
+	t.Parallel()
+
+	testData := []struct {
+		Policy StrengthPolicy
+		Weak   bool
+		Err    error
+	}{
+		{StrengthPolicy{MinBits: 0, Fail: true}, false, nil},
+		{StrengthPolicy{MinBits: 20, Fail: true}, false, nil},
+		{StrengthPolicy{MinBits: 30, Fail: false}, true, nil},
+		{StrengthPolicy{MinBits: 30, Fail: true}, false, ErrWeakPass},
+	}
+
+	for _, testCase := range testData {
+		entropy := Entropy{Selection: 20, Dictionary: 50, Weak: false}
+
+		if err := entropy.check(&testCase.Policy); !errors.Is(err, testCase.Err) {
+			t.Errorf("Expected %v for %+v; got: %v", testCase.Err, testCase.Policy, err)
+		}
+
+		if entropy.Weak != testCase.Weak {
+			t.Errorf("Expected weak %v for %+v", testCase.Weak, testCase.Policy)
+		}
+	}
+
+	config := DefaultConfig()
+	config.Strength.MinBits = -1
+
+	if err := config.Validate(); !errors.Is(err, ErrBadStrength) {
+		t.Errorf("Expected bad strength error; got: %v", err)
+	}
+}
@@ -1,54 +1,49 @@
 package app
 
 import (
+	"unicode/utf8"
+
 	pkgerr "github.com/pkg/errors"
-	"morphbits.io/app/usecase/combin"
-	"morphbits.io/app/usecase/utils"
 )
 
-func getLenCombinations(distDict []int, length, minPassLen, maxPassLen int) [][]int {
-	gen := combin.NewGenerator(distDict, length, func(l []int) bool {
-		s := utils.Sum(l...)
-		return s >= minPassLen && s <= maxPassLen
-	})
-
-	const preallocated = 1024
-
-	lenCombinations := make([][]int, 0, preallocated)
-	for gen.Next() {
-		lenCombinations = append(lenCombinations, gen.Combination(nil))
-	}
-
-	return lenCombinations
+// WordCost returns the cost of typing the word after its first character, as the passphrase search measures it.
+func WordCost(word string, calc DistanceCalculator) (int, error) {
+	return calcInternalDistance(word, calc)
 }
 
+// calcInternalDistance returns the cost of typing the word after its first character.
 func calcInternalDistance(word string, calc DistanceCalculator) (int, error) {
-	const minWordSize = 2
-
-	if len(word) < minWordSize {
-		return 0, nil
+	if seqCalc, ok := calc.(SequenceCalculator); ok {
+		return calcInternalSequenceDistance(word, seqCalc)
 	}
 
 	distance := 0
+	prev := rune(-1)
 
-	for i := 1; i < len(word); i++ {
-		a := word[i-1]
-		b := word[i]
+	for _, char := range word {
+		if prev >= 0 {
+			d, err := calc.GetDistance(prev, char)
+			if err != nil {
+				return 0, pkgerr.Wrapf(err, "error occurred while calculating distance for word '%s'", word)
+			}
 
-		d, err := calc.GetDistance(a, b)
-		if err != nil {
-			return 0, pkgerr.Wrapf(err, "error occurred while calculating distance for word '%s'", word)
+			distance += d
 		}
 
-		distance += d
+		prev = char
 	}
 
 	return distance, nil
 }
 
+// calcWordDistance returns the cost of moving from the end of the first word to the start of the second one.
 func calcWordDistance(word1, word2 string, calc DistanceCalculator) (int, error) {
-	a := word1[len(word1)-1]
-	b := word2[0]
+	if seqCalc, ok := calc.(SequenceCalculator); ok {
+		return calcWordSequenceDistance(word1, word2, seqCalc)
+	}
+
+	a := lastChar(word1)
+	b := firstChar(word2)
 
 	distance, err := calc.GetDistance(a, b)
 	if err != nil {
@@ -59,13 +54,159 @@ func calcWordDistance(word1, word2 string, calc DistanceCalculator) (int, error)
 	return distance, nil
 }
 
-// getWords makes groups of words with the given length.
-func getWords(wordsByLen wordLenMap, lenIdx [passWords]int) *[passWords][]wItem {
-	var words [passWords][]wItem
+// calcJoinDistance is the travel from one word to the next one through the separator. It returns
+// the cheapest of the separator choices with its cost.
+func calcJoinDistance(word1, word2 string, separator *Separator, calc DistanceCalculator) (int, string, error) {
+	best, bestChoice := 0, ""
+
+	for i, choice := range separator.choices(calc) {
+		dist, err := calcSeparatedDistance(word1, word2, choice, calc)
+		if err != nil {
+			return 0, "", err
+		}
+
+		if i == 0 || dist < best {
+			best, bestChoice = dist, choice
+		}
+	}
+
+	return best, bestChoice, nil
+}
+
+// calcSeparatedDistance is the cost of typing the separator between the words.
+func calcSeparatedDistance(word1, word2, separator string, calc DistanceCalculator) (int, error) {
+	if separator == "" {
+		return calcWordDistance(word1, word2, calc)
+	}
+
+	toSeparator, err := calcWordDistance(word1, separator, calc)
+	if err != nil {
+		return 0, err
+	}
+
+	internal, err := calcInternalDistance(separator, calc)
+	if err != nil {
+		return 0, err
+	}
+
+	fromSeparator, err := calcWordDistance(separator, word2, calc)
+	if err != nil {
+		return 0, err
+	}
+
+	return toSeparator + internal + fromSeparator, nil
+}
+
+// calcStartDistances is the cost of starting the pass with each of the words: the travel from
+// the start key, if any, or the cost of the first keystroke, if the calculator has one.
+// The finger rests on the start key, it is not pressed.
+func calcStartDistances(words []wItem, start rune, calc DistanceCalculator) ([]int, error) {
+	dist := make([]int, len(words))
+
+	keyCalc, hasKeyCost := calc.(KeyCostCalculator)
+	if start == 0 && !hasKeyCost {
+		return dist, nil
+	}
+
+	for i := 0; i < len(words); i++ {
+		var (
+			d   int
+			err error
+		)
+
+		first := firstChar(words[i].Data)
+
+		switch {
+		case start != 0 && first != start:
+			d, err = calcWordDistance(string(start), words[i].Data, calc)
+		case hasKeyCost:
+			// No travel to the first key, only from its modifier
+			d, err = keyCalc.GetKeyCost(first)
+			err = pkgerr.Wrapf(err, "error occurred while calculating distance for word '%s'", words[i].Data)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		dist[i] = d
+	}
+
+	return dist, nil
+}
+
+// calcEndDistances is the travel from each of the words to the end key, zeros without the end key.
+func calcEndDistances(words []wItem, end rune, calc DistanceCalculator) ([]int, error) {
+	dist := make([]int, len(words))
+	if end == 0 {
+		return dist, nil
+	}
+
+	for i := 0; i < len(words); i++ {
+		d, err := calcWordDistance(words[i].Data, string(end), calc)
+		if err != nil {
+			return nil, err
+		}
+
+		dist[i] = d
+	}
+
+	return dist, nil
+}
+
+func calcInternalSequenceDistance(word string, calc SequenceCalculator) (int, error) {
+	if word == "" {
+		return 0, nil
+	}
+
+	total, err := calc.GetSequenceDistance(word)
+	if err != nil {
+		return 0, pkgerr.Wrapf(err, "error occurred while calculating distance for word '%s'", word)
+	}
+
+	first, err := calc.GetSequenceDistance(string(firstChar(word)))
+	if err != nil {
+		return 0, pkgerr.Wrapf(err, "error occurred while calculating distance for word '%s'", word)
+	}
+
+	return total - first, nil
+}
+
+// calcWordSequenceDistance makes the internal distances of both words and the boundary
+// sum up to the cost of typing them one after another. The finger state after the first
+// word is taken into account, but not the state left by the words before it.
+func calcWordSequenceDistance(word1, word2 string, calc SequenceCalculator) (int, error) {
+	pair, err := calc.GetSequenceDistance(word1 + word2)
+	if err != nil {
+		return 0, pkgerr.Wrapf(err, "error occurred while calculating distance for words '%s', '%s'",
+			word1, word2)
+	}
+
+	first, err := calc.GetSequenceDistance(word1)
+	if err != nil {
+		return 0, pkgerr.Wrapf(err, "error occurred while calculating distance for words '%s', '%s'",
+			word1, word2)
+	}
 
-	for i := 0; i < len(lenIdx); i++ {
-		words[i] = wordsByLen[lenIdx[i]]
+	internal, err := calcInternalSequenceDistance(word2, calc)
+	if err != nil {
+		return 0, err
 	}
 
-	return &words
+	return pair - first - internal, nil
+}
+
+// wordLen returns the word length in characters.
+func wordLen(word string) int {
+	return utf8.RuneCountInString(word)
+}
+
+func firstChar(word string) rune {
+	char, _ := utf8.DecodeRuneInString(word)
+	return char
+}
+
+func lastChar(word string) rune {
+	char, _ := utf8.DecodeLastRuneInString(word)
+	return char
 }
@@ -1,35 +1,55 @@
 package app
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/golang/mock/gomock"
-	"golang.org/x/exp/constraints"
 	mockApp "morphbits.io/app/usecase/app/mock"
+	"morphbits.io/app/usecase/keyboard"
 )
 
-func Test_getLenCombinations(t *testing.T) {
+func Test_calcInternalDistance(t *testing.T) {
 	t.Parallel()
 
-	dict := []int{1, 3, 5}
-	length := 2
-	minPassLen := 5
-	maxPassLen := 6
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
 
-	expected := [][]int{
-		{1, 5},
-		{3, 3},
-		{5, 1},
+	calc := mockApp.NewMockDistanceCalculator(ctrl)
+
+	calc.EXPECT().GetDistance('w', 'o').Return(1, nil)
+	calc.EXPECT().GetDistance('o', 'r').Return(2, nil)
+	calc.EXPECT().GetDistance('r', 'd').Return(3, nil)
+
+	dist, err := calcInternalDistance("word", calc)
+	if err != nil {
+		t.Fatal(err)
 	}
 
-	got := getLenCombinations(dict, length, minPassLen, maxPassLen)
+	if expected := 1 + 2 + 3; dist != expected {
+		t.Errorf("Expected: %v, got: %v", expected, dist)
+	}
+}
+
+func Test_calcInternalDistance_zero(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	calc := mockApp.NewMockDistanceCalculator(ctrl)
+
+	dist, err := calcInternalDistance("a", calc)
+	if err != nil {
+		t.Fatal(err)
+	}
 
-	if !eqSlice2(expected, got) {
-		t.Errorf("Bad length combinations.\nExpected: %v\nGot: %v", expected, got)
+	if expected := 0; dist != expected {
+		t.Errorf("Expected: %v, got: %v", expected, dist)
 	}
 }
 
-func Test_calcInternalDistance(t *testing.T) {
+func Test_calcInternalDistance_runes(t *testing.T) {
 	t.Parallel()
 
 	ctrl := gomock.NewController(t)
@@ -37,21 +57,20 @@ func Test_calcInternalDistance(t *testing.T) {
 
 	calc := mockApp.NewMockDistanceCalculator(ctrl)
 
-	calc.EXPECT().GetDistance(uint8('w'), uint8('o')).Return(1, nil)
-	calc.EXPECT().GetDistance(uint8('o'), uint8('r')).Return(2, nil)
-	calc.EXPECT().GetDistance(uint8('r'), uint8('d')).Return(3, nil)
+	calc.EXPECT().GetDistance('м', 'и').Return(1, nil)
+	calc.EXPECT().GetDistance('и', 'р').Return(2, nil)
 
-	dist, err := calcInternalDistance("word", calc)
+	dist, err := calcInternalDistance("мир", calc)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	if expected := 1 + 2 + 3; dist != expected {
+	if expected := 1 + 2; dist != expected {
 		t.Errorf("Expected: %v, got: %v", expected, dist)
 	}
 }
 
-func Test_calcInternalDistance_zero(t *testing.T) {
+func Test_calcWordDistance_runes(t *testing.T) {
 	t.Parallel()
 
 	ctrl := gomock.NewController(t)
@@ -59,32 +78,158 @@ func Test_calcInternalDistance_zero(t *testing.T) {
 
 	calc := mockApp.NewMockDistanceCalculator(ctrl)
 
-	dist, err := calcInternalDistance("a", calc)
+	calc.EXPECT().GetDistance('р', 'ж').Return(5, nil)
+
+	dist, err := calcWordDistance("мир", "жук", calc)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	if expected := 0; dist != expected {
+	if expected := 5; dist != expected {
 		t.Errorf("Expected: %v, got: %v", expected, dist)
 	}
+
+	if expected := 3; wordLen("мир") != expected {
+		t.Errorf("Expected length: %v, got: %v", expected, wordLen("мир"))
+	}
+}
+
+type seqCalc struct {
+	*mockApp.MockDistanceCalculator
+	*mockApp.MockSequenceCalculator
+}
+
+func Test_calcSequenceDistance(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	calc := seqCalc{
+		MockDistanceCalculator: mockApp.NewMockDistanceCalculator(ctrl),
+		MockSequenceCalculator: mockApp.NewMockSequenceCalculator(ctrl),
+	}
+
+	calc.MockSequenceCalculator.EXPECT().GetSequenceDistance("ab").Return(5, nil).AnyTimes()
+	calc.MockSequenceCalculator.EXPECT().GetSequenceDistance("a").Return(1, nil).AnyTimes()
+	calc.MockSequenceCalculator.EXPECT().GetSequenceDistance("cd").Return(4, nil).AnyTimes()
+	calc.MockSequenceCalculator.EXPECT().GetSequenceDistance("c").Return(2, nil).AnyTimes()
+	calc.MockSequenceCalculator.EXPECT().GetSequenceDistance("abcd").Return(12, nil).AnyTimes()
+
+	internal, err := calcInternalDistance("ab", calc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := 5 - 1; internal != expected {
+		t.Errorf("Expected: %v, got: %v", expected, internal)
+	}
+
+	boundary, err := calcWordDistance("ab", "cd", calc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The whole sequence cost without the first keystroke
+	if expected := 12 - 1 - internal - (4 - 2); boundary != expected {
+		t.Errorf("Expected: %v, got: %v", expected, boundary)
+	}
 }
 
-func eqSlice2[A constraints.Ordered](a, b [][]A) bool {
-	if len(a) != len(b) {
-		return false
+func Test_calcStartDistances(t *testing.T) {
+	t.Parallel()
+
+	def, err := keyboard.Lookup("qwerty")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	def = def.Clone()
+	def.Effort = &keyboard.Effort{Press: 1, Keys: nil, Rows: nil, Fingers: nil, Reach: 0, Repeat: 2}
+
+	kbd, err := keyboard.NewFromDefinition(def)
+	if err != nil {
+		t.Fatal(err)
 	}
 
-	for i := 0; i < len(a); i++ {
-		if len(a[i]) != len(b[i]) {
-			return false
+	words := []wItem{{Data: "as", Dist: 0}, {Data: "ds", Dist: 0}}
+
+	testData := []struct {
+		Start    rune
+		Expected []int
+	}{
+		{0, []int{1, 1}},
+		{'a', []int{1, 3}}, // Neither the start key is pressed nor the repeat is charged
+		{'s', []int{2, 2}},
+	}
+
+	for _, testCase := range testData {
+		dist, err := calcStartDistances(words, testCase.Start, kbd)
+		if err != nil {
+			t.Fatal(err)
 		}
 
-		for j := 0; j < len(a[i]); j++ {
-			if a[i][j] != b[i][j] {
-				return false
-			}
+		if fmt.Sprint(dist) != fmt.Sprint(testCase.Expected) {
+			t.Errorf("Expected %v from '%c'; got: %v", testCase.Expected, testCase.Start, dist)
+		}
+	}
+}
+
+func Test_calcPassSequence(t *testing.T) {
+	t.Parallel()
+
+	kbd, err := keyboard.NewQWERTY()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	touch, err := keyboard.NewTouchTyping(kbd, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	words := make([]wItem, 0, 3)
+
+	for _, word := range []string{"fed", "jug", "deft"} {
+		internal, err := calcInternalDistance(word, touch)
+		if err != nil {
+			t.Fatal(err)
 		}
+
+		words = append(words, wItem{Data: word, Dist: internal})
 	}
 
-	return true
+	for _, terminals := range []Terminals{
+		{Start: 0, End: 0, Separator: noSeparator},
+		{Start: 0, End: 0, Separator: hyphenSeparator},
+		{Start: 'k', End: '\n', Separator: hyphenSeparator},
+	} {
+		pass, err := calcPass(words, touch, &terminals)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The fingers are where the words before have left them
+		expected, err := calcTextDistance(pass.Text, &terminals, touch)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		parts := pass.Start + pass.End
+		for _, cost := range append(append([]int(nil), pass.WordCosts...), pass.Boundaries...) {
+			parts += cost
+		}
+
+		if pass.Cost != expected || parts != expected {
+			t.Errorf("Expected '%s' cost %d; got: %d, the parts sum up to %d", pass.Text, expected, pass.Cost, parts)
+		}
+
+		if terminals.Start != 0 {
+			continue
+		}
+
+		if seq, err := touch.GetSequenceDistance(pass.Text); err != nil || seq != pass.Cost {
+			t.Errorf("Expected '%s' cost of the whole sequence %d; got: %d, %v", pass.Text, pass.Cost, seq, err)
+		}
+	}
 }
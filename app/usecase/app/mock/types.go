@@ -5,6 +5,7 @@
 package mock_app
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -34,17 +35,17 @@ func (m *MockDictReader) EXPECT() *MockDictReaderMockRecorder {
 }
 
 // Run mocks base method.
-func (m *MockDictReader) Run(handler func(string) error) error {
+func (m *MockDictReader) Run(ctx context.Context, handler func(string) error) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Run", handler)
+	ret := m.ctrl.Call(m, "Run", ctx, handler)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Run indicates an expected call of Run.
-func (mr *MockDictReaderMockRecorder) Run(handler interface{}) *gomock.Call {
+func (mr *MockDictReaderMockRecorder) Run(ctx, handler interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockDictReader)(nil).Run), handler)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockDictReader)(nil).Run), ctx, handler)
 }
 
 // MockDistanceCalculator is a mock of DistanceCalculator interface.
@@ -71,7 +72,7 @@ func (m *MockDistanceCalculator) EXPECT() *MockDistanceCalculatorMockRecorder {
 }
 
 // GetDistance mocks base method.
-func (m *MockDistanceCalculator) GetDistance(a, b byte) (int, error) {
+func (m *MockDistanceCalculator) GetDistance(a, b rune) (int, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetDistance", a, b)
 	ret0, _ := ret[0].(int)
@@ -85,6 +86,135 @@ func (mr *MockDistanceCalculatorMockRecorder) GetDistance(a, b interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDistance", reflect.TypeOf((*MockDistanceCalculator)(nil).GetDistance), a, b)
 }
 
+// IsMapped mocks base method.
+func (m *MockDistanceCalculator) IsMapped(a rune) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsMapped", a)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsMapped indicates an expected call of IsMapped.
+func (mr *MockDistanceCalculatorMockRecorder) IsMapped(a interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsMapped", reflect.TypeOf((*MockDistanceCalculator)(nil).IsMapped), a)
+}
+
+// MockSequenceCalculator is a mock of SequenceCalculator interface.
+type MockSequenceCalculator struct {
+	ctrl     *gomock.Controller
+	recorder *MockSequenceCalculatorMockRecorder
+}
+
+// MockSequenceCalculatorMockRecorder is the mock recorder for MockSequenceCalculator.
+type MockSequenceCalculatorMockRecorder struct {
+	mock *MockSequenceCalculator
+}
+
+// NewMockSequenceCalculator creates a new mock instance.
+func NewMockSequenceCalculator(ctrl *gomock.Controller) *MockSequenceCalculator {
+	mock := &MockSequenceCalculator{ctrl: ctrl}
+	mock.recorder = &MockSequenceCalculatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSequenceCalculator) EXPECT() *MockSequenceCalculatorMockRecorder {
+	return m.recorder
+}
+
+// GetSequenceDistance mocks base method.
+func (m *MockSequenceCalculator) GetSequenceDistance(seq string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSequenceDistance", seq)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSequenceDistance indicates an expected call of GetSequenceDistance.
+func (mr *MockSequenceCalculatorMockRecorder) GetSequenceDistance(seq interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSequenceDistance", reflect.TypeOf((*MockSequenceCalculator)(nil).GetSequenceDistance), seq)
+}
+
+// MockKeyCostCalculator is a mock of KeyCostCalculator interface.
+type MockKeyCostCalculator struct {
+	ctrl     *gomock.Controller
+	recorder *MockKeyCostCalculatorMockRecorder
+}
+
+// MockKeyCostCalculatorMockRecorder is the mock recorder for MockKeyCostCalculator.
+type MockKeyCostCalculatorMockRecorder struct {
+	mock *MockKeyCostCalculator
+}
+
+// NewMockKeyCostCalculator creates a new mock instance.
+func NewMockKeyCostCalculator(ctrl *gomock.Controller) *MockKeyCostCalculator {
+	mock := &MockKeyCostCalculator{ctrl: ctrl}
+	mock.recorder = &MockKeyCostCalculatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockKeyCostCalculator) EXPECT() *MockKeyCostCalculatorMockRecorder {
+	return m.recorder
+}
+
+// GetKeyCost mocks base method.
+func (m *MockKeyCostCalculator) GetKeyCost(char rune) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKeyCost", char)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKeyCost indicates an expected call of GetKeyCost.
+func (mr *MockKeyCostCalculatorMockRecorder) GetKeyCost(char interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKeyCost", reflect.TypeOf((*MockKeyCostCalculator)(nil).GetKeyCost), char)
+}
+
+// MockKeyLocator is a mock of KeyLocator interface.
+type MockKeyLocator struct {
+	ctrl     *gomock.Controller
+	recorder *MockKeyLocatorMockRecorder
+}
+
+// MockKeyLocatorMockRecorder is the mock recorder for MockKeyLocator.
+type MockKeyLocatorMockRecorder struct {
+	mock *MockKeyLocator
+}
+
+// NewMockKeyLocator creates a new mock instance.
+func NewMockKeyLocator(ctrl *gomock.Controller) *MockKeyLocator {
+	mock := &MockKeyLocator{ctrl: ctrl}
+	mock.recorder = &MockKeyLocatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockKeyLocator) EXPECT() *MockKeyLocatorMockRecorder {
+	return m.recorder
+}
+
+// Locate mocks base method.
+func (m *MockKeyLocator) Locate(char rune) (float64, float64, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Locate", char)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(float64)
+	ret2, _ := ret[2].(bool)
+	return ret0, ret1, ret2
+}
+
+// Locate indicates an expected call of Locate.
+func (mr *MockKeyLocatorMockRecorder) Locate(char interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Locate", reflect.TypeOf((*MockKeyLocator)(nil).Locate), char)
+}
+
 // MockMetrics is a mock of Metrics interface.
 type MockMetrics struct {
 	ctrl     *gomock.Controller
@@ -120,6 +250,18 @@ func (mr *MockMetricsMockRecorder) IncFilteredWords() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncFilteredWords", reflect.TypeOf((*MockMetrics)(nil).IncFilteredWords))
 }
 
+// IncSkippedWords mocks base method.
+func (m *MockMetrics) IncSkippedWords() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "IncSkippedWords")
+}
+
+// IncSkippedWords indicates an expected call of IncSkippedWords.
+func (mr *MockMetricsMockRecorder) IncSkippedWords() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncSkippedWords", reflect.TypeOf((*MockMetrics)(nil).IncSkippedWords))
+}
+
 // IncWords mocks base method.
 func (m *MockMetrics) IncWords() {
 	m.ctrl.T.Helper()
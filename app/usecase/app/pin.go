@@ -0,0 +1,291 @@
+package app
+
+import (
+	"context"
+	"math"
+
+	pkgerr "github.com/pkg/errors"
+	"morphbits.io/app/usecase/utils"
+)
+
+const (
+	pinDigits    = "0123456789"
+	maxPINLength = 12
+	minPINLength = 2
+	maxBestPINs  = 10 // The PINs of the same best distance returned at most
+	lineEps      = 1e-9
+)
+
+// getBestPIN looks for the PINs with the shortest distance, which pass the policy guardrails.
+// It is the depth-first search, the branches which can't finish within the distance of the best
+// PINs found are cut off, the ties as well once there are enough of them. When the context is done,
+// the PINs found so far are returned and reported partial.
+func getBestPIN(ctx context.Context, calc DistanceCalculator, policy *PINPolicy) ([]wItem, bool, error) {
+	if err := validatePINPolicy(policy); err != nil {
+		return nil, false, err
+	}
+
+	digits := make([]rune, 0, len(pinDigits))
+
+	for _, digit := range pinDigits {
+		if calc.IsMapped(digit) {
+			digits = append(digits, digit)
+		}
+	}
+
+	if len(digits) < policy.MinDistinct {
+		return nil, false, pkgerr.Wrapf(ErrBadPINPolicy, "only %d digits on the layout", len(digits))
+	}
+
+	dist := make([][]int, len(digits))
+
+	for i := 0; i < len(digits); i++ {
+		dist[i] = make([]int, len(digits))
+
+		for j := 0; j < len(digits); j++ {
+			d, err := calc.GetDistance(digits[i], digits[j])
+			if err != nil {
+				return nil, false, pkgerr.Wrap(err, "error occurred while calculating distance between digits")
+			}
+
+			dist[i][j] = d
+		}
+	}
+
+	search := pinSearch{
+		policy:   policy,
+		digits:   digits,
+		dist:     dist,
+		rest:     nil,
+		lines:    nil,
+		idx:      make([]int, policy.Length),
+		used:     make([]int, len(digits)),
+		distinct: 0,
+		bestDist: utils.MaxInt(),
+		best:     nil,
+
+		interrupted: false,
+	}
+
+	// On the layouts with all the digits in a row every PIN is a line, the guardrail is for the keypads
+	if locator, ok := calc.(KeyLocator); ok && !policy.AllowLines && !isLine(digits, locator) {
+		search.lines = locator
+	}
+
+	search.calcRest()
+	search.run(ctx, 0, 0)
+
+	if len(search.best) == 0 && search.interrupted {
+		return nil, false, pkgerr.Wrap(ctx.Err(), "no PIN found before the search is interrupted")
+	}
+
+	if len(search.best) == 0 {
+		return nil, false, pkgerr.Wrapf(ErrBadPINPolicy, "no PIN of %d digits passes the guardrails", policy.Length)
+	}
+
+	return search.best, search.interrupted, nil
+}
+
+func validatePINPolicy(policy *PINPolicy) error {
+	if policy.Length < minPINLength || policy.Length > maxPINLength {
+		return pkgerr.Wrapf(ErrBadPINPolicy, "length %d is out of [%d, %d]", policy.Length, minPINLength, maxPINLength)
+	}
+
+	if policy.MinDistinct > policy.Length || policy.MinDistinct > len(pinDigits) {
+		return pkgerr.Wrapf(ErrBadPINPolicy, "%d distinct digits in %d digits PIN", policy.MinDistinct, policy.Length)
+	}
+
+	return nil
+}
+
+type pinSearch struct {
+	policy *PINPolicy
+	digits []rune
+	dist   [][]int
+	rest   [][]int    // By the position and the digit there, the shortest distance to the end
+	lines  KeyLocator // Nil if the PINs on a line are allowed
+	idx    []int
+
+	used     []int // Times each digit is in the PIN so far
+	distinct int   // Different digits in the PIN so far
+
+	bestDist int
+	best     []wItem
+
+	interrupted bool // The context is done, the PINs found may be not the best ones
+}
+
+// calcRest finds the shortest distance to finish the PIN from every position and digit,
+// without the guardrails which need the whole PIN.
+func (s *pinSearch) calcRest() {
+	s.rest = make([][]int, len(s.idx))
+	s.rest[len(s.idx)-1] = make([]int, len(s.digits))
+
+	for pos := len(s.idx) - 2; pos >= 0; pos-- {
+		s.rest[pos] = make([]int, len(s.digits))
+
+		for i := 0; i < len(s.digits); i++ {
+			best := utils.MaxInt()
+
+			for j := 0; j < len(s.digits); j++ {
+				if i == j && !s.policy.AllowRepeats {
+					continue
+				}
+
+				if d := s.dist[i][j] + s.rest[pos+1][j]; d < best {
+					best = d
+				}
+			}
+
+			s.rest[pos][i] = best
+		}
+	}
+}
+
+// cut reports whether the PIN of the distance can't get into the best ones.
+func (s *pinSearch) cut(dist int) bool {
+	if len(s.best) < maxBestPINs {
+		return dist > s.bestDist
+	}
+
+	return dist >= s.bestDist
+}
+
+func (s *pinSearch) run(ctx context.Context, pos, dist int) {
+	if s.interrupted || ctx.Err() != nil {
+		s.interrupted = true
+		return
+	}
+
+	if pos == len(s.idx) {
+		pin := make([]rune, len(s.idx))
+		for i := 0; i < len(s.idx); i++ {
+			pin[i] = s.digits[s.idx[i]]
+		}
+
+		if !s.acceptable(pin) {
+			return
+		}
+
+		if dist < s.bestDist {
+			s.bestDist = dist
+			s.best = nil
+		}
+
+		if !s.cut(dist) {
+			s.best = append(s.best, wItem{Data: string(pin), Dist: dist})
+		}
+
+		return
+	}
+
+	for i := 0; i < len(s.digits); i++ {
+		step := 0
+
+		if pos > 0 {
+			if !s.policy.AllowRepeats && s.idx[pos-1] == i {
+				continue
+			}
+
+			step = s.dist[s.idx[pos-1]][i]
+		}
+
+		if s.cut(dist+step+s.rest[pos][i]) || !s.distinctFits(pos, i) {
+			continue
+		}
+
+		s.idx[pos] = i
+		s.push(i)
+		s.run(ctx, pos+1, dist+step)
+		s.pop(i)
+	}
+}
+
+// distinctFits reports whether the PIN may still have enough different digits with the digit at the position.
+func (s *pinSearch) distinctFits(pos, digit int) bool {
+	distinct := s.distinct
+	if s.used[digit] == 0 {
+		distinct++
+	}
+
+	return distinct+len(s.idx)-pos-1 >= s.policy.MinDistinct
+}
+
+func (s *pinSearch) push(digit int) {
+	if s.used[digit] == 0 {
+		s.distinct++
+	}
+
+	s.used[digit]++
+}
+
+func (s *pinSearch) pop(digit int) {
+	s.used[digit]--
+
+	if s.used[digit] == 0 {
+		s.distinct--
+	}
+}
+
+// acceptable checks the guardrails, which need the whole PIN.
+func (s *pinSearch) acceptable(pin []rune) bool {
+	distinct := make(map[rune]bool, len(pin))
+	for _, digit := range pin {
+		distinct[digit] = true
+	}
+
+	if len(distinct) < s.policy.MinDistinct {
+		return false
+	}
+
+	if !s.policy.AllowSequences && isSequence(pin) {
+		return false
+	}
+
+	if s.lines != nil && isLine(pin, s.lines) {
+		return false
+	}
+
+	return true
+}
+
+// isSequence reports whether the digits go with the constant step, wrapping around 9.
+func isSequence(pin []rune) bool {
+	const base = 10
+
+	if len(pin) < minPINLength {
+		return false
+	}
+
+	step := (pin[1] - pin[0] + base) % base
+
+	for i := 2; i < len(pin); i++ {
+		if (pin[i]-pin[i-1]+base)%base != step {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isLine reports whether all the keys lie on a straight line.
+func isLine(pin []rune, locator KeyLocator) bool {
+	x0, y0, _ := locator.Locate(pin[0])
+
+	var dx, dy float64
+
+	for _, digit := range pin[1:] {
+		x, y, _ := locator.Locate(digit)
+
+		if dx == 0 && dy == 0 {
+			dx, dy = x-x0, y-y0
+			continue
+		}
+
+		if math.Abs(dx*(y-y0)-dy*(x-x0)) > lineEps {
+			return false
+		}
+	}
+
+	return true
+}
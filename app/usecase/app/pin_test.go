@@ -0,0 +1,225 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"morphbits.io/app/usecase/utils"
+)
+
+// phonePad is the phone keypad with Manhattan distance.
+type phonePad struct{}
+
+var phonePadKeys = map[rune][2]float64{
+	'1': {0, 0}, '2': {1, 0}, '3': {2, 0},
+	'4': {0, 1}, '5': {1, 1}, '6': {2, 1},
+	'7': {0, 2}, '8': {1, 2}, '9': {2, 2},
+	'0': {1, 3},
+}
+
+func (phonePad) GetDistance(a, b rune) (int, error) {
+	pa, pb := phonePadKeys[a], phonePadKeys[b]
+	return int(math.Abs(pa[0]-pb[0]) + math.Abs(pa[1]-pb[1])), nil
+}
+
+func (phonePad) IsMapped(a rune) bool {
+	_, ok := phonePadKeys[a]
+	return ok
+}
+
+func (phonePad) Locate(a rune) (x, y float64, ok bool) {
+	p, ok := phonePadKeys[a]
+	return p[0], p[1], ok
+}
+
+func Test_getBestPIN(t *testing.T) {
+	t.Parallel()
+
+	policy := DefaultConfig().PIN
+
+	pins, _, err := getBestPIN(context.Background(), phonePad{}, &policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found := false
+
+	for _, pin := range pins {
+		if pin.Dist != 3 {
+			t.Errorf("Expected distance 3, got %v", pin)
+		}
+
+		if pin.Data == "1254" {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Errorf("Expected '1254' among the best PINs, got %v", pins)
+	}
+}
+
+func Test_getBestPINGuardrails(t *testing.T) {
+	t.Parallel()
+
+	testData := []struct {
+		PIN      string
+		Policy   PINPolicy
+		Expected bool
+	}{
+		{"1254", PINPolicy{4, 3, false, false, false}, true},
+		{"1111", PINPolicy{4, 1, true, true, true}, true},
+		{"1121", PINPolicy{4, 2, true, true, true}, true},
+		{"1212", PINPolicy{4, 3, true, true, true}, false}, // Only 2 distinct digits
+		{"1234", PINPolicy{4, 3, true, false, true}, false},
+		{"8901", PINPolicy{4, 3, true, false, true}, false},
+		{"9753", PINPolicy{4, 3, true, false, true}, false},
+		{"2580", PINPolicy{4, 3, true, true, false}, false},
+		{"1590", PINPolicy{4, 3, true, true, false}, true},
+		{"1593", PINPolicy{4, 3, true, true, false}, true},
+		{"7535", PINPolicy{4, 3, true, true, false}, false}, // Diagonal
+	}
+
+	for _, testCase := range testData {
+		policy := testCase.Policy
+		search := pinSearch{policy: &policy} //nolint:exhaustruct // only the guardrails
+		if !policy.AllowLines {
+			search.lines = phonePad{}
+		}
+
+		if got := search.acceptable([]rune(testCase.PIN)); got != testCase.Expected {
+			t.Errorf("PIN '%s', policy %+v: expected %v, got %v", testCase.PIN, testCase.Policy, testCase.Expected, got)
+		}
+	}
+}
+
+func Test_getBestPINRepeats(t *testing.T) {
+	t.Parallel()
+
+	policy := PINPolicy{Length: 6, MinDistinct: 1, AllowRepeats: true, AllowSequences: true, AllowLines: true}
+
+	pins, _, err := getBestPIN(context.Background(), phonePad{}, &policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pins) != len(pinDigits) || pins[0].Dist != 0 {
+		t.Errorf("Expected all the same digit PINs, got %v", pins)
+	}
+
+	policy.Length = 1
+
+	if _, _, err := getBestPIN(context.Background(), phonePad{}, &policy); !errors.Is(err, ErrBadPINPolicy) {
+		t.Errorf("Expected error '%v', got '%v'", ErrBadPINPolicy, err)
+	}
+}
+
+func Test_getBestPINMaxLength(t *testing.T) {
+	t.Parallel()
+
+	policy := DefaultConfig().PIN
+	policy.Length = maxPINLength
+
+	pins, _, err := getBestPIN(context.Background(), phonePad{}, &policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pins) == 0 || len(pins) > maxBestPINs {
+		t.Fatalf("Expected up to %d PINs, got %v", maxBestPINs, pins)
+	}
+
+	// Every keystroke to the neighbour key
+	for _, pin := range pins {
+		if pin.Dist != maxPINLength-1 || len(pin.Data) != maxPINLength {
+			t.Errorf("Expected %d digits of distance %d, got %v", maxPINLength, maxPINLength-1, pin)
+		}
+	}
+}
+
+// rowPad is the row of digits, every PIN is on a line.
+type rowPad struct{}
+
+func (rowPad) GetDistance(a, b rune) (int, error) {
+	return int(math.Abs(float64(a - b))), nil
+}
+
+func (rowPad) IsMapped(a rune) bool {
+	return a >= '0' && a <= '9'
+}
+
+func (rowPad) Locate(a rune) (x, y float64, ok bool) {
+	return float64(a - '0'), 0, a >= '0' && a <= '9'
+}
+
+func Test_getBestPINNone(t *testing.T) {
+	t.Parallel()
+
+	// The line guardrail is for the keypads, the row of digits passes it
+	policy := DefaultConfig().PIN
+
+	pins, _, err := getBestPIN(context.Background(), rowPad{}, &policy)
+	if err != nil || len(pins) == 0 {
+		t.Errorf("Expected the PINs on the row of digits, got %v, %v", pins, err)
+	}
+
+	// Any 2 digits go with the constant step
+	policy = PINPolicy{Length: 2, MinDistinct: 2, AllowRepeats: false, AllowSequences: false, AllowLines: true}
+
+	if _, _, err := getBestPIN(context.Background(), rowPad{}, &policy); !errors.Is(err, ErrBadPINPolicy) {
+		t.Errorf("Expected error '%v', got '%v'", ErrBadPINPolicy, err)
+	}
+}
+
+func Test_getBestPINInterrupted(t *testing.T) {
+	t.Parallel()
+
+	policy := DefaultConfig().PIN
+	policy.Length = maxPINLength
+
+	all := newCountdownCtx(utils.MaxInt())
+
+	if _, partial, err := getBestPIN(all, phonePad{}, &policy); err != nil || partial {
+		t.Fatalf("Expected the complete search; got: %v, %v", partial, err)
+	}
+
+	// Stopped at the last node, the PINs are found before it
+	pins, partial, err := getBestPIN(newCountdownCtx(all.calls-1), phonePad{}, &policy)
+	if err != nil || !partial || len(pins) == 0 {
+		t.Errorf("Expected the partial PINs; got: %v, %v, %v", pins, partial, err)
+	}
+
+	if _, _, err := getBestPIN(newCountdownCtx(0), phonePad{}, &policy); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the deadline error without a PIN found; got: %v", err)
+	}
+}
+
+func Test_getBestPINAllDigits(t *testing.T) {
+	t.Parallel()
+
+	policy := DefaultConfig().PIN
+	policy.Length = maxPINLength
+	policy.MinDistinct = len(pinDigits)
+
+	pins, _, err := getBestPIN(context.Background(), phonePad{}, &policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, pin := range pins {
+		distinct := make(map[rune]bool)
+		for _, digit := range pin.Data {
+			distinct[digit] = true
+		}
+
+		if len(distinct) != len(pinDigits) {
+			t.Errorf("Expected all the digits in %v", pin)
+		}
+	}
+
+	if len(pins) == 0 {
+		t.Error("Expected the PINs with all the digits")
+	}
+}
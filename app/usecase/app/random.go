@@ -0,0 +1,163 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"io"
+	"math/big"
+	"sort"
+
+	pkgerr "github.com/pkg/errors"
+)
+
+// maxPool bounds the number of the passes enumerated for the random pick.
+const maxPool = 10_000_000
+
+// passSampler picks one of the passes within the cost ceiling uniformly with the reservoir
+// sampling: the n-th pass found replaces the picked one with the probability 1/n.
+type passSampler struct {
+	random  io.Reader
+	ceiling int
+	pool    int
+	picked  []wItem
+	err     error
+}
+
+func (p *passSampler) record(path []wItem) {
+	if p.pool == maxPool {
+		p.err = pkgerr.Wrapf(ErrPoolTooLarge, "more than %d, lower the budget", maxPool)
+		return
+	}
+
+	p.pool++
+
+	n, err := rand.Int(p.random, big.NewInt(int64(p.pool)))
+	if err != nil {
+		p.err = pkgerr.Wrap(err, "failed read random")
+		return
+	}
+
+	if n.Sign() == 0 {
+		p.picked = append(p.picked[:0], path...)
+	}
+}
+
+// sample picks the pass among the ones which cost at most the budget more than the cheapest one.
+// It returns the pass words and the number of the passes it is picked from, zero if there is no pass.
+// When the context is done it picks among the passes enumerated so far.
+func (s *passSolver) sample(ctx context.Context, budget int, random io.Reader) ([]wItem, int, error) {
+	// Without the cheapest pass there is no ceiling to enumerate the passes within
+	best := s.solve(ctx)
+	if len(best) == 0 || s.interrupted {
+		return nil, 0, nil
+	}
+
+	sampler := &passSampler{
+		random:  random,
+		ceiling: best[0].cost + budget,
+		pool:    0,
+		picked:  nil,
+		err:     nil,
+	}
+
+	s.enumerate(ctx, 0, 0, 0, 0, sampler)
+
+	return sampler.picked, sampler.pool, sampler.err
+}
+
+// enumerate passes every pass within the ceiling to the sampler. The order does not matter here,
+// so unlike search it goes through the words as they are.
+func (s *passSolver) enumerate(ctx context.Context, i, used, prev, cost int, sampler *passSampler) {
+	if s.stopped(ctx) {
+		return
+	}
+
+	if i == s.policy.Words {
+		sampler.record(s.path)
+		return
+	}
+
+	for c := 0; c < len(s.classes) && sampler.err == nil && !s.interrupted; c++ {
+		class := &s.classes[c]
+
+		for w := 0; w < len(class.words) && sampler.err == nil && !s.interrupted; w++ {
+			if s.taken[c][w] {
+				continue
+			}
+
+			estimate, ok := s.estimate(i, used, prev, class, w)
+			if !ok || cost+estimate > sampler.ceiling {
+				break
+			}
+
+			word := class.words[w]
+
+			s.taken[c][w] = s.policy.Unique
+
+			s.path = append(s.path, word)
+			s.enumerate(ctx, i+1, used+class.key.length, class.last, cost+s.step(i, prev, class, word), sampler)
+			s.path = s.path[:len(s.path)-1]
+
+			s.taken[c][w] = false
+		}
+	}
+}
+
+// trim drops the words which can't be in a pass within the budget of the cheapest one. The pass has
+// fewer other words than the cheapest words kept, so one of them is free to replace the dropped word,
+// and that pass is cheaper by more than the budget.
+func (c wordClasses) trim(words, budget int) {
+	for key, class := range c {
+		if len(class) <= words {
+			continue
+		}
+
+		ceiling := class[words-1].Dist + budget
+		c[key] = class[:sort.Search(len(class), func(i int) bool { return class[i].Dist > ceiling })]
+	}
+}
+
+// getRandomPass picks the pass uniformly among the ones within the budget of the cheapest one and
+// applies the composition rules to it. It returns the pass and the number of the passes it is picked from.
+// When the context is done before all of them are enumerated, the pass is picked among the ones found
+// and reported partial.
+func getRandomPass(
+	ctx context.Context, words wordClasses, calc DistanceCalculator, terminals *Terminals, policy *PassPolicy,
+	compose *ComposePolicy, random io.Reader,
+) (*Pass, int, bool, error) {
+	words.trim(policy.Words, policy.Budget)
+
+	// Only the cheapest pass is needed for the ceiling
+	optimum := *compose.window(policy, terminals, calc)
+	optimum.Results = 1
+
+	solver, err := newPassSolver(words, calc, terminals, &optimum)
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	picked, pool, err := solver.sample(ctx, policy.Budget, random)
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	if pool == 0 && solver.interrupted {
+		return nil, 0, false, pkgerr.Wrap(ctx.Err(), "no pass found before the search is interrupted")
+	}
+
+	if pool == 0 {
+		return nil, 0, false, pkgerr.Wrapf(ErrNoPass, "%d words of %d-%d characters",
+			policy.Words, policy.MinLength, policy.MaxLength)
+	}
+
+	pass, err := calcPass(picked, calc, terminals)
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	if err := composePass(pass, calc, terminals, compose, policy); err != nil {
+		return nil, 0, false, err
+	}
+
+	return pass, pool, solver.interrupted, nil
+}
@@ -0,0 +1,154 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"testing"
+	"testing/iotest"
+
+	"morphbits.io/app/usecase/keyboard"
+	"morphbits.io/app/usecase/utils"
+)
+
+func Test_wordClassesTrim(t *testing.T) {
+	t.Parallel()
+
+	classes := make(wordClasses)
+	for i, word := range []string{"abc", "adc", "aec", "afc", "agc"} {
+		classes.add(wItem{Data: word, Dist: i * 2}, utils.MaxInt())
+	}
+
+	// The second cheapest costs 2, so up to 5 is kept with the budget of 3
+	classes.trim(2, 3)
+
+	class := classes[classKey{length: 3, first: 'a', last: 'c'}]
+	if len(class) != 3 || class[2].Data != "aec" {
+		t.Errorf("Expected 'abc', 'adc' and 'aec'; got: %v", class)
+	}
+}
+
+func Test_getRandomPass(t *testing.T) {
+	t.Parallel()
+
+	kbd, err := keyboard.NewQWERTY()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dictionary := []string{
+		"ad", "lk", "fg", "hj", "kl", "jk", "jhk", "jgk", "jyk", "juk", "jik",
+		"asd", "afd", "lol", "pop", "qwe", "trew", "asdf",
+	}
+
+	terminals := Terminals{Start: 'l', End: 0, Separator: hyphenSeparator}
+
+	for _, budget := range []int{0, 2, 5, 10} {
+		for _, unique := range []bool{false, true} {
+			policy := PassPolicy{
+				Words:     3,
+				MinLength: 7,
+				MaxLength: 9,
+				Unique:    unique,
+				Results:   1,
+				Random:    true,
+				Budget:    budget,
+			}
+
+			classes, words := newTestClasses(t, kbd, policy.classLimit(), dictionary...)
+
+			all := policy
+			all.Results = utils.MaxInt()
+
+			costs := bruteForcePass(t, words, kbd, &terminals, &all)
+			expected := 0
+
+			for _, cost := range costs {
+				if cost <= costs[0]+budget {
+					expected++
+				}
+			}
+
+			pass, pool, _, err := getRandomPass(context.Background(), classes, kbd, &terminals, &policy, &noCompose, rand.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if pool != expected {
+				t.Errorf("Expected the pool of %d for the budget %d, unique %v; got: %d", expected, budget, unique, pool)
+			}
+
+			if pass.Cost > costs[0]+budget {
+				t.Errorf("Expected '%s' to cost at most %d; got: %d", pass.Text, costs[0]+budget, pass.Cost)
+			}
+		}
+	}
+}
+
+func Test_getRandomPassErrors(t *testing.T) {
+	t.Parallel()
+
+	kbd, err := keyboard.NewQWERTY()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	policy := PassPolicy{Words: 2, MinLength: 4, MaxLength: 4, Unique: true, Results: 1, Random: true, Budget: 1}
+
+	classes, _ := newTestClasses(t, kbd, policy.classLimit(), "ad", "fg", "hj")
+
+	readErr := errors.New("no entropy")
+	_, _, _, err = getRandomPass(
+		context.Background(), classes, kbd, &Terminals{}, &policy, &noCompose, iotest.ErrReader(readErr))
+	if !errors.Is(err, readErr) {
+		t.Errorf("Expected the read error; got: %v", err)
+	}
+
+	classes, _ = newTestClasses(t, kbd, policy.classLimit(), "asd", "fgh")
+	_, _, _, err = getRandomPass(context.Background(), classes, kbd, &Terminals{}, &policy, &noCompose, rand.Reader)
+	if !errors.Is(err, ErrNoPass) {
+		t.Errorf("Expected no pass error; got: %v", err)
+	}
+}
+
+func Test_getRandomPassInterrupted(t *testing.T) {
+	t.Parallel()
+
+	kbd, err := keyboard.NewQWERTY()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	policy := PassPolicy{Words: 3, MinLength: 6, MaxLength: 9, Unique: true, Results: 1, Random: true, Budget: 10}
+	dictionary := []string{"ad", "lk", "fg", "hj", "kl", "jk", "asd", "afd", "lol", "pop"}
+
+	classes, _ := newTestClasses(t, kbd, policy.classLimit(), dictionary...)
+
+	all := newCountdownCtx(utils.MaxInt())
+
+	_, pool, partial, err := getRandomPass(all, classes, kbd, &Terminals{}, &policy, &noCompose, rand.Reader)
+	if err != nil || partial {
+		t.Fatalf("Expected the complete pool; got: %v, %v", partial, err)
+	}
+
+	// Interrupted at the last pass enumerated, the pass is picked among the ones before it
+	classes, _ = newTestClasses(t, kbd, policy.classLimit(), dictionary...)
+
+	pass, partialPool, partial, err := getRandomPass(
+		newCountdownCtx(all.calls-1), classes, kbd, &Terminals{}, &policy, &noCompose, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !partial || partialPool != pool-1 || pass == nil {
+		t.Errorf("Expected the partial pool of %d; got: %d, %v", pool-1, partialPool, partial)
+	}
+
+	classes, _ = newTestClasses(t, kbd, policy.classLimit(), dictionary...)
+
+	// Done from the start, nothing is found
+	_, _, _, err = getRandomPass(newCountdownCtx(0), classes, kbd, &Terminals{}, &policy, &noCompose, rand.Reader)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the deadline error; got: %v", err)
+	}
+}
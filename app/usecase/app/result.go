@@ -0,0 +1,250 @@
+package app
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Result is what Run found: the passphrases or the PINs from the cheapest one.
+type Result struct {
+	Config  Config
+	Layout  string // Name of the layout, empty if the calculator does not know it
+	Passes  []Pass
+	Pool    int  // Number of the passes the random one is picked from, zero if not random
+	Partial bool // The search is interrupted, the passes are the best found, not the cheapest ones
+	Entropy Entropy
+}
+
+// Pass is the passphrase with the cost of each of its parts. The PIN is the pass of a single word.
+type Pass struct {
+	Text       string   // As typed, the words with the separators and the composition rules
+	Base       string   // The text before the composition rules, the parts below are of it
+	Words      []string // In the typing order
+	Separators []string // Typed between the neighbour words, empty without the separator
+	WordCosts  []int    // Internal cost of each word
+	Boundaries []int    // Cost between the neighbour words, the separator included
+	Start      int      // Travel from the start key the finger rests on and the first keystroke
+	End        int      // Cost of the end key
+	Extra      int      // Cost added by the composition rules
+	Cost       int      // The total cost
+	Length     int      // Number of characters, the separators included
+}
+
+// LayoutNamer is implemented by the calculators which know the name of their layout.
+type LayoutNamer interface {
+	Name() string
+}
+
+// newResult makes the result for the calculator's layout.
+func newResult(config *Config, calc DistanceCalculator, passes []Pass) *Result {
+	layout := ""
+	if namer, ok := calc.(LayoutNamer); ok {
+		layout = namer.Name()
+	}
+
+	return &Result{
+		Config:  *config,
+		Layout:  layout,
+		Passes:  passes,
+		Pool:    0,
+		Partial: false,
+		Entropy: Entropy{Selection: 0, Dictionary: 0, Weak: false},
+	}
+}
+
+// calcPass measures every part of typing the words one after another with the terminal keys.
+// The parts are split from the keystroke costs of the whole pass, so the stateful cost models
+// see the fingers where all the keys before have left them.
+func calcPass(words []wItem, calc DistanceCalculator, terminals *Terminals) (*Pass, error) {
+	pass := &Pass{
+		Text:       "",
+		Base:       "",
+		Words:      make([]string, 0, len(words)),
+		Separators: make([]string, 0, len(words)-1),
+		WordCosts:  make([]int, 0, len(words)),
+		Boundaries: make([]int, 0, len(words)-1),
+		Start:      0,
+		End:        0,
+		Extra:      0,
+		Cost:       0,
+		Length:     0,
+	}
+
+	var text strings.Builder
+
+	ends := make([]int, 0, len(words)) // Number of the keystrokes up to the end of every word
+
+	for i := 0; i < len(words); i++ {
+		if i > 0 {
+			_, separator, err := calcJoinDistance(words[i-1].Data, words[i].Data, &terminals.Separator, calc)
+			if err != nil {
+				return nil, err
+			}
+
+			pass.Separators = append(pass.Separators, separator)
+			pass.Length += wordLen(separator)
+			text.WriteString(separator)
+		}
+
+		pass.Words = append(pass.Words, words[i].Data)
+		pass.Length += wordLen(words[i].Data)
+		ends = append(ends, pass.Length)
+		text.WriteString(words[i].Data)
+	}
+
+	pass.Text = text.String()
+	pass.Base = pass.Text
+
+	costs, err := KeystrokeCosts(pass.Text, terminals, calc)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := func(from, to int) int {
+		total := 0
+		for _, cost := range costs[from:to] {
+			total += cost
+		}
+
+		return total
+	}
+
+	pass.Start = costs[0]
+	pass.End = sum(pass.Length, len(costs))
+
+	for i, end := range ends {
+		first := end - wordLen(words[i].Data)
+		if i > 0 {
+			pass.Boundaries = append(pass.Boundaries, sum(ends[i-1], first+1))
+		}
+
+		pass.WordCosts = append(pass.WordCosts, sum(first+1, end))
+	}
+
+	pass.Cost = sum(0, len(costs))
+
+	return pass, nil
+}
+
+// KeystrokeCosts splits the cost of typing the text with the terminal keys into the keystrokes,
+// the end key is the last one if there is any. The first keystroke carries the travel from
+// the start key. The costs are of the same measure as the pass, so they sum up to its cost.
+func KeystrokeCosts(text string, terminals *Terminals, calc DistanceCalculator) ([]int, error) {
+	costs := make([]int, 0, wordLen(text)+1)
+
+	if text == "" {
+		return costs, nil
+	}
+
+	first := firstChar(text)
+
+	start, err := calcStartDistances([]wItem{{Data: string(first), Dist: 0}}, terminals.Start, calc)
+	if err != nil {
+		return nil, err
+	}
+
+	costs = append(costs, start[0])
+
+	// Every keystroke is measured after all the keys before it, from the start key the finger rests on
+	typed, offset := text, 0
+	if terminals.Start != 0 && first != terminals.Start {
+		typed = string(terminals.Start) + text
+		offset = utf8.RuneLen(terminals.Start)
+	}
+
+	for i := range text {
+		if i == 0 {
+			continue
+		}
+
+		_, size := utf8.DecodeRuneInString(text[i:])
+
+		cost, err := calcWordDistance(typed[:offset+i], text[i:i+size], calc)
+		if err != nil {
+			return nil, err
+		}
+
+		costs = append(costs, cost)
+	}
+
+	if terminals.End == 0 {
+		return costs, nil
+	}
+
+	end, err := calcEndDistances([]wItem{{Data: text, Dist: 0}}, terminals.End, calc)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(costs, end[0]), nil
+}
+
+// pinPass is the PIN as the pass of a single word.
+func pinPass(pin wItem) Pass {
+	return Pass{
+		Text:       pin.Data,
+		Base:       pin.Data,
+		Words:      []string{pin.Data},
+		Separators: nil,
+		WordCosts:  []int{pin.Dist},
+		Boundaries: nil,
+		Start:      0,
+		End:        0,
+		Extra:      0,
+		Cost:       pin.Dist,
+		Length:     wordLen(pin.Data),
+	}
+}
+
+// composePass applies the composition rules to the pass within the length window of the policy.
+func composePass(
+	pass *Pass, calc DistanceCalculator, terminals *Terminals, compose *ComposePolicy, policy *PassPolicy,
+) error {
+	if !compose.required() {
+		return nil
+	}
+
+	text, err := newComposer(calc, terminals, compose).compose(pass.Text, policy.MinLength, policy.MaxLength)
+	if err != nil {
+		return err
+	}
+
+	base, err := calcTextDistance(pass.Text, terminals, calc)
+	if err != nil {
+		return err
+	}
+
+	composed, err := calcTextDistance(text, terminals, calc)
+	if err != nil {
+		return err
+	}
+
+	pass.Text = text
+	pass.Extra = composed - base
+	pass.Cost += pass.Extra
+	pass.Length = wordLen(text)
+
+	return nil
+}
+
+// calcTextDistance is the cost of typing the whole text with the start and end keys.
+func calcTextDistance(text string, terminals *Terminals, calc DistanceCalculator) (int, error) {
+	item := []wItem{{Data: text, Dist: 0}}
+
+	start, err := calcStartDistances(item, terminals.Start, calc)
+	if err != nil {
+		return 0, err
+	}
+
+	internal, err := calcInternalDistance(text, calc)
+	if err != nil {
+		return 0, err
+	}
+
+	end, err := calcEndDistances(item, terminals.End, calc)
+	if err != nil {
+		return 0, err
+	}
+
+	return start[0] + internal + end[0], nil
+}
@@ -0,0 +1,104 @@
+package app
+
+import (
+	"errors"
+	"strings"
+
+	pkgerr "github.com/pkg/errors"
+)
+
+var ErrUnknownSeparator = errors.New("unknown separator")
+
+// SeparatorKind is what is typed between the passphrase words.
+type SeparatorKind int
+
+const (
+	NoSeparator     SeparatorKind = iota // The words are typed one after another
+	SpaceSeparator                       // The space bar
+	HyphenSeparator                      // The hyphen
+	DigitSeparator                       // The cheapest digit at every boundary
+	CustomSeparator                      // Any string
+)
+
+var separatorKindNames = map[SeparatorKind]string{
+	NoSeparator:     "none",
+	SpaceSeparator:  "space",
+	HyphenSeparator: "hyphen",
+	DigitSeparator:  "digit",
+	CustomSeparator: "custom",
+}
+
+// Separator is typed between the passphrase words. It is counted in the length and in the cost.
+type Separator struct {
+	Kind SeparatorKind
+	Text string // The custom separator
+}
+
+// ParseSeparator returns the separator by its kind name: none, space, hyphen or digit.
+// Any other non-empty string is the custom separator.
+func ParseSeparator(name string) (Separator, error) {
+	for kind, kindName := range separatorKindNames {
+		if kind != CustomSeparator && kindName == strings.ToLower(name) {
+			return Separator{Kind: kind, Text: ""}, nil
+		}
+	}
+
+	if name == "" {
+		return Separator{Kind: NoSeparator, Text: ""}, pkgerr.Wrap(ErrUnknownSeparator, "empty")
+	}
+
+	return Separator{Kind: CustomSeparator, Text: name}, nil
+}
+
+func (s Separator) String() string {
+	if s.Kind == CustomSeparator {
+		return s.Text
+	}
+
+	if name, ok := separatorKindNames[s.Kind]; ok {
+		return name
+	}
+
+	return "unknown"
+}
+
+// length is the number of characters typed at every boundary.
+func (s *Separator) length() int {
+	switch s.Kind {
+	case NoSeparator:
+		return 0
+	case CustomSeparator:
+		return wordLen(s.Text)
+	case SpaceSeparator, HyphenSeparator, DigitSeparator:
+	}
+
+	return 1
+}
+
+// choices are the strings which may be typed at the boundary, only the ones on the layout.
+func (s *Separator) choices(calc DistanceCalculator) []string {
+	var all []string
+
+	switch s.Kind {
+	case NoSeparator:
+		return []string{""}
+	case SpaceSeparator:
+		all = []string{" "}
+	case HyphenSeparator:
+		all = []string{"-"}
+	case DigitSeparator:
+		all = strings.Split(pinDigits, "")
+	case CustomSeparator:
+		all = []string{s.Text}
+	}
+
+	choices := make([]string, 0, len(all))
+
+	for _, choice := range all {
+		if isMapped(choice, calc) {
+			choices = append(choices, choice)
+		}
+	}
+
+	return choices
+}
@@ -0,0 +1,77 @@
+package app
+
+import (
+	"errors"
+	"testing"
+
+	"morphbits.io/app/usecase/keyboard"
+)
+
+func Test_ParseSeparator(t *testing.T) {
+	t.Parallel()
+
+	testData := []struct {
+		Name     string
+		Expected Separator
+		Length   int
+		Err      error
+	}{
+		{"none", Separator{Kind: NoSeparator, Text: ""}, 0, nil},
+		{"Space", Separator{Kind: SpaceSeparator, Text: ""}, 1, nil},
+		{"hyphen", Separator{Kind: HyphenSeparator, Text: ""}, 1, nil},
+		{"digit", Separator{Kind: DigitSeparator, Text: ""}, 1, nil},
+		{"custom", Separator{Kind: CustomSeparator, Text: "custom"}, 6, nil},
+		{"+=", Separator{Kind: CustomSeparator, Text: "+="}, 2, nil},
+		{"", Separator{Kind: NoSeparator, Text: ""}, 0, ErrUnknownSeparator},
+	}
+
+	for _, testCase := range testData {
+		separator, err := ParseSeparator(testCase.Name)
+		if !errors.Is(err, testCase.Err) {
+			t.Errorf("Expected %v for '%s'; got: %v", testCase.Err, testCase.Name, err)
+		}
+
+		if separator != testCase.Expected || separator.length() != testCase.Length {
+			t.Errorf("Expected %+v of %d characters for '%s'; got: %+v", testCase.Expected, testCase.Length,
+				testCase.Name, separator)
+		}
+	}
+}
+
+func Test_calcJoinDistanceSeparators(t *testing.T) {
+	t.Parallel()
+
+	kbd, err := keyboard.NewByName("qwerty")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testData := []struct {
+		Separator Separator
+		Choice    string
+		Dist      int
+	}{
+		{Separator{Kind: NoSeparator, Text: ""}, "", 0},
+		{Separator{Kind: SpaceSeparator, Text: ""}, " ", 16},
+		{Separator{Kind: HyphenSeparator, Text: ""}, "-", 22},
+		{Separator{Kind: DigitSeparator, Text: ""}, "1", 2},
+		{Separator{Kind: CustomSeparator, Text: "zq"}, "zq", 4},
+	}
+
+	for _, testCase := range testData {
+		dist, choice, err := calcJoinDistance("aq", "qa", &testCase.Separator, kbd)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if choice != testCase.Choice || dist != testCase.Dist {
+			t.Errorf("Expected '%s' of %d for %+v; got: '%s' of %d",
+				testCase.Choice, testCase.Dist, testCase.Separator, choice, dist)
+		}
+	}
+
+	terminals := Terminals{Start: 0, End: 0, Separator: Separator{Kind: CustomSeparator, Text: "a€"}}
+	if err := terminals.validate(kbd); !errors.Is(err, ErrUnmappedKey) {
+		t.Errorf("Expected unmapped key error; got: %v", err)
+	}
+}
@@ -0,0 +1,390 @@
+package app
+
+import (
+	"container/heap"
+	"context"
+	"sort"
+
+	"morphbits.io/app/usecase/utils"
+)
+
+// noPath marks the states from which no pass of the allowed length can be finished.
+var noPath = utils.MaxInt()
+
+// classKey groups the words which cost the same outside: with the pairwise cost models the
+// travel from the previous word and to the next one depends only on the first and the last characters.
+type classKey struct {
+	length      int
+	first, last rune
+}
+
+// wordClasses keeps the cheapest words of every class sorted by the internal distance.
+type wordClasses map[classKey][]wItem
+
+// add inserts the word into its class keeping up to limit words. It returns false if the word
+// is a duplicate or too expensive.
+func (c wordClasses) add(word wItem, limit int) bool {
+	key := classKey{length: wordLen(word.Data), first: firstChar(word.Data), last: lastChar(word.Data)}
+	class := c[key]
+
+	for i := 0; i < len(class); i++ {
+		if class[i].Data == word.Data {
+			return false
+		}
+	}
+
+	i := sort.Search(len(class), func(i int) bool { return class[i].Dist > word.Dist })
+	if i >= limit {
+		return false
+	}
+
+	class = utils.Insert(class, word, i)
+	if len(class) > limit {
+		class = class[:limit]
+	}
+
+	c[key] = class
+
+	return true
+}
+
+// wordClass is the class with its first and last characters indexes.
+type wordClass struct {
+	key         classKey
+	first, last int
+	words       []wItem
+}
+
+// passSolver finds the cheapest passes exactly. The shortest path over the states
+// (position, length typed, last character) with the cheapest word of each class is the pass
+// cost when the words may repeat. It is the lower bound of the cost with the unique words,
+// so the branch and bound search over the classes with these bounds finds the optimum.
+// The search keeps the bounded heap of the best passes found, the branches which can't beat
+// the worst of them are cut off.
+type passSolver struct {
+	calc      DistanceCalculator
+	terminals *Terminals
+	policy    *PassPolicy
+
+	// The length window of the words alone, without the separators, -1 if nothing fits
+	minLength, maxLength int
+
+	classes []wordClass
+	chars   int     // Number of the first and last characters
+	start   []int   // By the first character
+	end     []int   // By the last character
+	join    []int   // By the last and the next first characters
+	bounds  [][]int // By the position, up to the number of words
+
+	// The search state
+	best        passHeap
+	taken       [][]bool // Words of each class in the path
+	path        []wItem
+	interrupted bool // The context is done, the passes found may be not the cheapest ones
+}
+
+// scoredPass is the pass words with the cost.
+type scoredPass struct {
+	words []wItem
+	cost  int
+}
+
+// passHeap is the max-heap of the passes by the cost, the worst pass is on the top.
+type passHeap []scoredPass
+
+func (h passHeap) Len() int           { return len(h) }
+func (h passHeap) Less(i, j int) bool { return h[i].cost > h[j].cost }
+func (h passHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *passHeap) Push(x any)        { *h = append(*h, x.(scoredPass)) } //nolint:forcetypeassert // heap.Interface
+func (h *passHeap) Pop() any {
+	old := *h
+	last := old[len(old)-1]
+	*h = old[:len(old)-1]
+
+	return last
+}
+
+func newPassSolver(
+	classes wordClasses, calc DistanceCalculator, terminals *Terminals, policy *PassPolicy,
+) (*passSolver, error) {
+	s := &passSolver{
+		calc:      calc,
+		terminals: terminals,
+		policy:    policy,
+
+		minLength: 0,
+		maxLength: 0,
+
+		classes: make([]wordClass, 0, len(classes)),
+		chars:   0,
+		start:   nil,
+		end:     nil,
+		join:    nil,
+		bounds:  make([][]int, policy.Words+1),
+
+		best:        make(passHeap, 0, policy.Results),
+		taken:       nil,
+		path:        make([]wItem, 0, policy.Words),
+		interrupted: false,
+	}
+
+	charIdx := make(map[rune]int)
+	index := func(char rune) int {
+		if _, ok := charIdx[char]; !ok {
+			charIdx[char] = len(charIdx)
+		}
+
+		return charIdx[char]
+	}
+
+	keys := make([]classKey, 0, len(classes))
+	for key := range classes {
+		keys = append(keys, key)
+	}
+
+	// Sorted for the same result among the passes of the same cost
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.length != b.length {
+			return a.length < b.length
+		}
+
+		if a.first != b.first {
+			return a.first < b.first
+		}
+
+		return a.last < b.last
+	})
+
+	for _, key := range keys {
+		s.classes = append(s.classes, wordClass{
+			key:   key,
+			first: index(key.first),
+			last:  index(key.last),
+			words: classes[key],
+		})
+	}
+
+	separators := (policy.Words - 1) * terminals.Separator.length()
+	s.minLength = policy.MinLength - separators
+	s.maxLength = policy.MaxLength - separators
+
+	if s.maxLength < 0 {
+		s.maxLength = -1
+	}
+
+	s.chars = len(charIdx)
+	s.taken = make([][]bool, len(s.classes))
+	for c := 0; c < len(s.classes); c++ {
+		s.taken[c] = make([]bool, len(s.classes[c].words))
+	}
+
+	if err := s.calcCharCosts(charIdx); err != nil {
+		return nil, err
+	}
+
+	s.calcBounds()
+
+	return s, nil
+}
+
+// calcCharCosts measures the start, end and join costs between the characters.
+func (s *passSolver) calcCharCosts(charIdx map[rune]int) error {
+	chars := make([]wItem, s.chars)
+	for char, i := range charIdx {
+		chars[i] = wItem{Data: string(char), Dist: 0}
+	}
+
+	var err error
+
+	if s.start, err = calcStartDistances(chars, s.terminals.Start, s.calc); err != nil {
+		return err
+	}
+
+	if s.end, err = calcEndDistances(chars, s.terminals.End, s.calc); err != nil {
+		return err
+	}
+
+	s.join = make([]int, s.chars*s.chars)
+
+	for i := 0; i < s.chars; i++ {
+		for j := 0; j < s.chars; j++ {
+			s.join[i*s.chars+j], _, err = calcJoinDistance(chars[i].Data, chars[j].Data, &s.terminals.Separator, s.calc)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// calcBounds finds the cheapest way to finish the pass from every state with the repeating words.
+// bounds[i][used*chars+prev] is for the words from the i-th one, when used characters are typed
+// and the previous word ends with prev.
+func (s *passSolver) calcBounds() {
+	words, maxLen := s.policy.Words, s.maxLength
+	states := (maxLen + 1) * s.chars
+
+	s.bounds[words] = make([]int, states)
+	for used := 0; used <= maxLen; used++ {
+		for prev := 0; prev < s.chars; prev++ {
+			s.bounds[words][used*s.chars+prev] = noPath
+			if used >= s.minLength {
+				s.bounds[words][used*s.chars+prev] = 0
+			}
+		}
+	}
+
+	for i := words - 1; i >= 0; i-- {
+		s.bounds[i] = make([]int, states)
+
+		for used := 0; used <= maxLen; used++ {
+			for prev := 0; prev < s.chars; prev++ {
+				best := noPath
+
+				for c := 0; c < len(s.classes); c++ {
+					if cost, ok := s.estimate(i, used, prev, &s.classes[c], 0); ok && cost < best {
+						best = cost
+					}
+				}
+
+				s.bounds[i][used*s.chars+prev] = best
+			}
+		}
+	}
+}
+
+// estimate is the lowest cost of finishing the pass with the nth word of the class at the i-th position.
+func (s *passSolver) estimate(i, used, prev int, class *wordClass, nth int) (int, bool) {
+	next := used + class.key.length
+	if next > s.maxLength {
+		return 0, false
+	}
+
+	rest := s.bounds[i+1][next*s.chars+class.last]
+	if rest == noPath {
+		return 0, false
+	}
+
+	return s.step(i, prev, class, class.words[nth]) + rest, true
+}
+
+// step is the cost of typing the word of the class at the i-th position after the previous word.
+func (s *passSolver) step(i, prev int, class *wordClass, word wItem) int {
+	cost := word.Dist
+
+	if i == 0 {
+		cost += s.start[class.first]
+	} else {
+		cost += s.join[prev*s.chars+class.first]
+	}
+
+	if i == s.policy.Words-1 {
+		cost += s.end[class.last]
+	}
+
+	return cost
+}
+
+// candidate is the word of the class to try at the position with the lowest cost of the pass through it.
+type candidate struct {
+	class, word int
+	cost        int
+}
+
+// threshold is the cost the pass must be cheaper than to get into the results.
+func (s *passSolver) threshold() int {
+	if len(s.best) < s.policy.Results {
+		return noPath
+	}
+
+	return s.best[0].cost
+}
+
+// record adds the pass to the results, dropping the worst one if there are too many.
+func (s *passSolver) record(cost int) {
+	pass := scoredPass{words: append([]wItem(nil), s.path...), cost: cost}
+
+	if len(s.best) < s.policy.Results {
+		heap.Push(&s.best, pass)
+		return
+	}
+
+	s.best[0] = pass
+	heap.Fix(&s.best, 0)
+}
+
+// stopped checks the context at every node of the search and marks the search interrupted once it is done.
+func (s *passSolver) stopped(ctx context.Context) bool {
+	if !s.interrupted && ctx.Err() != nil {
+		s.interrupted = true
+	}
+
+	return s.interrupted
+}
+
+// search tries the words at the i-th position, the cheapest first, and skips the ones which can't
+// beat the passes found. The words of the class are sorted, so the rest of the class is skipped as well.
+func (s *passSolver) search(ctx context.Context, i, used, prev, cost int) {
+	if s.stopped(ctx) {
+		return
+	}
+
+	if i == s.policy.Words {
+		if cost < s.threshold() {
+			s.record(cost)
+		}
+
+		return
+	}
+
+	candidates := make([]candidate, 0, len(s.classes))
+
+	for c := 0; c < len(s.classes); c++ {
+		for w := 0; w < len(s.classes[c].words); w++ {
+			if s.taken[c][w] {
+				continue
+			}
+
+			estimate, ok := s.estimate(i, used, prev, &s.classes[c], w)
+			if !ok || cost+estimate >= s.threshold() {
+				break
+			}
+
+			candidates = append(candidates, candidate{class: c, word: w, cost: cost + estimate})
+		}
+	}
+
+	sort.SliceStable(candidates, func(a, b int) bool { return candidates[a].cost < candidates[b].cost })
+
+	for _, next := range candidates {
+		if next.cost >= s.threshold() || s.interrupted {
+			return
+		}
+
+		class := &s.classes[next.class]
+		word := class.words[next.word]
+
+		step := s.step(i, prev, class, word)
+
+		s.taken[next.class][next.word] = s.policy.Unique
+
+		s.path = append(s.path, word)
+		s.search(ctx, i+1, used+class.key.length, class.last, cost+step)
+		s.path = s.path[:len(s.path)-1]
+
+		s.taken[next.class][next.word] = false
+	}
+}
+
+// solve returns the words of the cheapest passes from the cheapest one, none if no pass has the allowed length.
+// When the context is done it stops with the passes found so far.
+func (s *passSolver) solve(ctx context.Context) []scoredPass {
+	s.search(ctx, 0, 0, 0, 0)
+
+	best := append([]scoredPass(nil), s.best...)
+	sort.SliceStable(best, func(i, j int) bool { return best[i].cost < best[j].cost })
+
+	return best
+}
@@ -0,0 +1,395 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+
+	"morphbits.io/app/usecase/keyboard"
+	"morphbits.io/app/usecase/utils"
+)
+
+var (
+	noSeparator     = Separator{Kind: NoSeparator, Text: ""}
+	hyphenSeparator = Separator{Kind: HyphenSeparator, Text: ""}
+	noCompose       = ComposePolicy{Upper: 0, Digits: 0, Symbols: 0, Charset: ""}
+)
+
+func newTestClasses(t *testing.T, calc DistanceCalculator, limit int, words ...string) (wordClasses, []wItem) {
+	t.Helper()
+
+	classes := make(wordClasses)
+	items := make([]wItem, 0, len(words))
+
+	for _, word := range words {
+		dist, err := calcInternalDistance(word, calc)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		classes.add(wItem{Data: word, Dist: dist}, limit)
+		items = append(items, wItem{Data: word, Dist: dist})
+	}
+
+	return classes, items
+}
+
+// bruteForcePass tries every sequence of the words and returns the cheapest costs.
+func bruteForcePass(
+	t *testing.T, words []wItem, calc DistanceCalculator, terminals *Terminals, policy *PassPolicy,
+) []int {
+	t.Helper()
+
+	var costs []int
+
+	idx := make([]int, policy.Words)
+	separators := (policy.Words - 1) * terminals.Separator.length()
+
+	var try func(i, length int)
+	try = func(i, length int) {
+		if i == policy.Words {
+			if length < policy.MinLength || length > policy.MaxLength {
+				return
+			}
+
+			pass := make([]wItem, 0, policy.Words)
+			for _, j := range idx {
+				pass = append(pass, words[j])
+			}
+
+			measured, err := calcPass(pass, calc, terminals)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			costs = append(costs, measured.Cost)
+
+			return
+		}
+
+	next:
+		for j := 0; j < len(words); j++ {
+			for k := 0; policy.Unique && k < i; k++ {
+				if idx[k] == j {
+					continue next
+				}
+			}
+
+			idx[i] = j
+			try(i+1, length+wordLen(words[j].Data))
+		}
+	}
+
+	try(0, separators)
+	sort.Ints(costs)
+
+	if len(costs) > policy.Results {
+		costs = costs[:policy.Results]
+	}
+
+	return costs
+}
+
+func Test_wordClassesAdd(t *testing.T) {
+	t.Parallel()
+
+	classes := make(wordClasses)
+
+	for i, word := range []string{"abc", "adc", "aec", "afc", "agc"} {
+		classes.add(wItem{Data: word, Dist: 10 - i}, 4)
+	}
+
+	if classes.add(wItem{Data: "agc", Dist: 6}, 4) {
+		t.Error("Expected the duplicate word to be dropped")
+	}
+
+	if classes.add(wItem{Data: "ahc", Dist: 100}, 4) {
+		t.Error("Expected the expensive word to be dropped")
+	}
+
+	class := classes[classKey{length: 3, first: 'a', last: 'c'}]
+	if len(class) != 4 || class[0].Data != "agc" || class[3].Data != "adc" {
+		t.Errorf("Expected 4 cheapest words from 'agc' to 'adc'; got: %v", class)
+	}
+}
+
+func Test_passSolverExact(t *testing.T) {
+	t.Parallel()
+
+	kbd, err := keyboard.NewQWERTY()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The classes with several words check the unique words and the dropped words
+	dictionary := []string{
+		"ad", "lk", "fg", "hj", "kl", "jk", "jhk", "jgk", "jyk", "juk", "jik",
+		"asd", "afd", "lol", "pop", "qwe", "trew", "asdf",
+	}
+
+	testData := []struct {
+		Terminals                      Terminals
+		Words, MinLen, MaxLen, Results int
+	}{
+		{Terminals{Start: 0, End: 0, Separator: noSeparator}, 4, 8, 8, 1},
+		{Terminals{Start: 0, End: 0, Separator: noSeparator}, 4, 12, 13, 3},
+		{Terminals{Start: 0, End: 0, Separator: noSeparator}, 2, 5, 6, 3},
+		{Terminals{Start: 0, End: 0, Separator: noSeparator}, 2, 6, 6, 4},
+		{Terminals{Start: 0, End: 0, Separator: noSeparator}, 5, 11, 14, 2},
+		{Terminals{Start: 'l', End: 0, Separator: noSeparator}, 4, 8, 10, 5},
+		{Terminals{Start: 'l', End: '\n', Separator: noSeparator}, 3, 9, 11, 3},
+		{Terminals{Start: 'l', End: '\n', Separator: hyphenSeparator}, 4, 10, 12, 3},
+	}
+
+	for _, testCase := range testData {
+		terminals := testCase.Terminals
+
+		for _, unique := range []bool{false, true} {
+			policy := PassPolicy{
+				Words:     testCase.Words,
+				MinLength: testCase.MinLen,
+				MaxLength: testCase.MaxLen,
+				Unique:    unique,
+				Results:   testCase.Results,
+				Random:    false,
+				Budget:    0,
+			}
+
+			classes, _ := newTestClasses(t, kbd, policy.classLimit(), dictionary...)
+			_, words := newTestClasses(t, kbd, len(dictionary), dictionary...)
+
+			solver, err := newPassSolver(classes, kbd, &terminals, &policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			passes := solver.solve(context.Background())
+			costs := make([]int, 0, len(passes))
+
+			for _, pass := range passes {
+				measured, err := calcPass(pass.words, kbd, &terminals)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if measured.Cost != pass.cost {
+					t.Errorf("Expected the solver cost %d to match the pass %v cost %d", pass.cost, pass.words, measured.Cost)
+				}
+
+				costs = append(costs, measured.Cost)
+			}
+
+			expected := bruteForcePass(t, words, kbd, &terminals, &policy)
+			if fmt.Sprint(costs) != fmt.Sprint(expected) {
+				t.Errorf("Expected %v for %+v, unique %v; got: %v", expected, testCase, unique, costs)
+			}
+		}
+	}
+
+	policy := PassPolicy{Words: 4, MinLength: 100, MaxLength: 100, Unique: true, Results: 1, Random: false, Budget: 0}
+	classes, _ := newTestClasses(t, kbd, policy.classLimit(), dictionary...)
+
+	solver, err := newPassSolver(classes, kbd, &Terminals{Start: 0, End: 0, Separator: noSeparator}, &policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if passes := solver.solve(context.Background()); len(passes) != 0 {
+		t.Errorf("Expected no pass of 100 characters; got: %v", passes)
+	}
+}
+
+func Test_getBestPassTerminals(t *testing.T) {
+	t.Parallel()
+
+	kbd, err := keyboard.NewQWERTY()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	policy := DefaultConfig().Pass
+	policy.Results = 2
+
+	classes, _ := newTestClasses(t, kbd, policy.classLimit(), "asdfg", "hjkl", "qwert", "poiuy", "zxcvb")
+
+	ctx := context.Background()
+	terminals := Terminals{Start: 0, End: 0, Separator: hyphenSeparator}
+
+	passes, _, err := getBestPass(ctx, classes, kbd, &terminals, &policy, &noCompose)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// With the separators counted, the 4 letters word fits into the length window
+	expected := []string{"qwert-hjkl-poiuy-asdfg", "qwert-poiuy-hjkl-asdfg"}
+	for i, pass := range passes {
+		if i >= len(expected) || pass.Text != expected[i] || pass.Cost != 53 {
+			t.Errorf("Expected %v of 53; got: %+v", expected, passes)
+		}
+
+		sum := pass.Start + pass.End
+		for _, cost := range append(pass.WordCosts, pass.Boundaries...) {
+			sum += cost
+		}
+
+		if sum != pass.Cost || pass.Length != 22 || len(pass.Boundaries) != 3 {
+			t.Errorf("Expected the parts of %+v to sum up to the cost", pass)
+		}
+	}
+
+	if len(passes) != len(expected) {
+		t.Errorf("Expected %d passes; got: %d", len(expected), len(passes))
+	}
+
+	_, _, err = getBestPass(ctx, make(wordClasses), kbd, &Terminals{}, &policy, &noCompose)
+	if !errors.Is(err, ErrNoPass) {
+		t.Errorf("Expected no pass error; got: %v", err)
+	}
+
+	terminals = Terminals{Start: 0, End: '`', Separator: noSeparator}
+	if err := terminals.validate(kbd); !errors.Is(err, ErrUnmappedKey) {
+		t.Errorf("Expected unmapped key error; got: %v", err)
+	}
+}
+
+func Test_getBestPassStartEnd(t *testing.T) {
+	t.Parallel()
+
+	kbd, err := keyboard.NewQWERTY()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	policy := PassPolicy{Words: 4, MinLength: 8, MaxLength: 11, Unique: true, Results: 1, Random: false, Budget: 0}
+	classes, words := newTestClasses(t, kbd, policy.classLimit(), "ad", "lk", "fg", "hj", "kl")
+
+	testData := []struct {
+		Terminals Terminals
+		Text      string
+		Cost      int
+	}{
+		{Terminals{Start: 0, End: 0, Separator: noSeparator}, "fghjkllk", 6},
+		{Terminals{Start: 'l', End: 0, Separator: noSeparator}, "lkklhjfg", 10},
+		{Terminals{Start: 'l', End: '\n', Separator: noSeparator}, "lkfghjkl", 13},
+		{Terminals{Start: 'l', End: '\n', Separator: hyphenSeparator}, "kl-lk-hj-fg", 47},
+	}
+
+	for _, testCase := range testData {
+		terminals := testCase.Terminals
+
+		passes, _, err := getBestPass(context.Background(), classes, kbd, &terminals, &policy, &noCompose)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if passes[0].Text != testCase.Text || passes[0].Cost != testCase.Cost {
+			t.Errorf("Expected '%s' of %d for %+v; got: %+v", testCase.Text, testCase.Cost, terminals, passes[0])
+		}
+
+		if costs := bruteForcePass(t, words, kbd, &terminals, &policy); costs[0] != testCase.Cost {
+			t.Errorf("Expected the brute force cost %d for %+v; got: %d", testCase.Cost, terminals, costs[0])
+		}
+	}
+}
+
+func Test_getBestPassEffort(t *testing.T) {
+	t.Parallel()
+
+	def, err := keyboard.Lookup("qwerty")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	def = def.Clone()
+	def.Effort = &keyboard.Effort{Press: 1, Keys: nil, Rows: nil, Fingers: nil, Reach: 0, Repeat: 0}
+
+	kbd, err := keyboard.NewFromDefinition(def)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	policy := PassPolicy{Words: 4, MinLength: 8, MaxLength: 8, Unique: true, Results: 1, Random: false, Budget: 0}
+	classes, _ := newTestClasses(t, kbd, policy.classLimit(), "ad", "fg", "hj", "kl")
+
+	solver, err := newPassSolver(classes, kbd, &Terminals{Start: 0, End: 0, Separator: noSeparator}, &policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The travel of 8 and every one of the 8 keystrokes, the first one included
+	passes := solver.solve(context.Background())
+	if len(passes) != 1 || passes[0].cost != 16 || passes[0].words[0].Data != "ad" {
+		t.Errorf("Expected 'ad fg hj kl' of 16; got: %v", passes)
+	}
+}
+
+// countdownCtx is done after the number of the checks, so the search is interrupted at the known node.
+type countdownCtx struct {
+	context.Context
+	checks int
+	calls  int
+}
+
+func newCountdownCtx(checks int) *countdownCtx {
+	return &countdownCtx{Context: context.Background(), checks: checks, calls: 0}
+}
+
+func (c *countdownCtx) Err() error {
+	c.calls++
+
+	if c.calls > c.checks {
+		return context.DeadlineExceeded
+	}
+
+	return nil
+}
+
+func Test_getBestPassInterrupted(t *testing.T) {
+	t.Parallel()
+
+	kbd, err := keyboard.NewQWERTY()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	policy := DefaultConfig().Pass
+	policy.Results = 2
+
+	terminals := Terminals{Start: 0, End: 0, Separator: hyphenSeparator}
+	classes, _ := newTestClasses(t, kbd, policy.classLimit(), "asdfg", "hjkl", "qwert", "poiuy", "zxcvb")
+
+	// The whole search, to count the nodes
+	all := newCountdownCtx(utils.MaxInt())
+
+	passes, partial, err := getBestPass(all, classes, kbd, &terminals, &policy, &noCompose)
+	if err != nil || partial || len(passes) != 2 {
+		t.Fatalf("Expected 2 passes of the complete search; got: %v, %v, %v", passes, partial, err)
+	}
+
+	// Interrupted at the last node, the passes found so far are returned
+	passes, partial, err = getBestPass(newCountdownCtx(all.calls-1), classes, kbd, &terminals, &policy, &noCompose)
+	if err != nil || !partial || len(passes) == 0 || passes[0].Cost < 53 {
+		t.Errorf("Expected the passes found before the interruption; got: %+v, %v, %v", passes, partial, err)
+	}
+
+	// Done from the start, nothing is found
+	_, _, err = getBestPass(newCountdownCtx(0), classes, kbd, &terminals, &policy, &noCompose)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the deadline error; got: %v", err)
+	}
+
+	// Three unique words don't make a pass of four, the search ends with the context all the same
+	classes, _ = newTestClasses(t, kbd, policy.classLimit(), "asdfg", "hjkl", "qwert")
+	all = newCountdownCtx(utils.MaxInt())
+
+	if _, _, err = getBestPass(all, classes, kbd, &terminals, &policy, &noCompose); !errors.Is(err, ErrNoPass) {
+		t.Fatalf("Expected no pass of the complete search; got: %v", err)
+	}
+
+	_, _, err = getBestPass(newCountdownCtx(all.calls/2), classes, kbd, &terminals, &policy, &noCompose)
+	if !errors.Is(err, context.DeadlineExceeded) || all.calls < 2 {
+		t.Errorf("Expected the deadline error; got: %v after %d nodes", err, all.calls)
+	}
+}
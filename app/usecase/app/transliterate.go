@@ -0,0 +1,58 @@
+package app
+
+import "strings"
+
+// transliteration replaces the accented Latin letters with their ASCII look-alikes.
+var transliteration = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a",
+	'æ': "ae",
+	'ç': "c", 'č': "c",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i",
+	'ñ': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o",
+	'œ': "oe",
+	'š': "s", 'ß': "ss",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u",
+	'ý': "y", 'ÿ': "y",
+	'ž': "z",
+}
+
+// droppable characters are removed from the words while transliterating.
+const droppable = "'’-. "
+
+// transliterate rewrites the word with the characters from the layout. It returns false
+// if some characters can be neither mapped, nor transliterated, nor dropped.
+func transliterate(word string, calc DistanceCalculator) (string, bool) {
+	var result strings.Builder
+
+	result.Grow(len(word))
+
+	for _, char := range word {
+		switch {
+		case calc.IsMapped(char):
+			result.WriteRune(char)
+		case strings.ContainsRune(droppable, char):
+			continue
+		default:
+			replacement, ok := transliteration[char]
+			if !ok || !isMapped(replacement, calc) {
+				return "", false
+			}
+
+			result.WriteString(replacement)
+		}
+	}
+
+	return result.String(), true
+}
+
+func isMapped(word string, calc DistanceCalculator) bool {
+	for _, char := range word {
+		if !calc.IsMapped(char) {
+			return false
+		}
+	}
+
+	return true
+}
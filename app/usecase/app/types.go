@@ -1,28 +1,41 @@
 package app
 
-const (
-	uniqueWords    = true // Use only unique words in the password
-	bestWordsCount = 12   // Words with the shortest distance for each word length
-	passWords      = 4    // Number of words in the password
-	minPassLength  = 20   // Minimum password length
-	maxPassLength  = 24   // Maximum password length
-)
-
-type wordLenMap map[int][]wItem
+import "context"
 
 //go:generate mockgen -source=$GOFILE -destination=mock/$GOFILE
 
+// DictReader passes the dictionary words to the handler until the context is done.
 type DictReader interface {
-	Run(handler func(word string) error) error
+	Run(ctx context.Context, handler func(word string) error) error
 }
 
 type DistanceCalculator interface {
-	GetDistance(a, b byte) (int, error)
+	GetDistance(a, b rune) (int, error)
+	IsMapped(a rune) bool
+}
+
+// SequenceCalculator is implemented by the stateful cost models, e.g. multi-finger typing,
+// where the cost of a keystroke depends on the whole typed sequence, not only on the previous key.
+// The cost includes the first keystroke from the initial position.
+type SequenceCalculator interface {
+	GetSequenceDistance(seq string) (int, error)
+}
+
+// KeyCostCalculator is implemented by the calculators with the keypress effort. The first
+// keystroke of the pass has no previous key, so its cost is taken separately.
+type KeyCostCalculator interface {
+	GetKeyCost(char rune) (int, error)
+}
+
+// KeyLocator is implemented by the calculators which know the key positions.
+type KeyLocator interface {
+	Locate(char rune) (x, y float64, ok bool)
 }
 
 type Metrics interface {
 	IncWords()
 	IncFilteredWords()
+	IncSkippedWords()
 }
 
 // wItem store the word itself and it's internal distance.
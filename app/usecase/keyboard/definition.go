@@ -0,0 +1,444 @@
+package keyboard
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	pkgerr "github.com/pkg/errors"
+)
+
+// DefinitionVersion is the only layout file format version understood by the loader.
+const DefinitionVersion = 1
+
+// DefaultAlphabet is used to validate layout files which don't declare their own alphabet.
+const DefaultAlphabet = "abcdefghijklmnopqrstuvwxyz"
+
+var (
+	ErrBadVersion     = errors.New("unsupported layout version")
+	ErrEmptyLayout    = errors.New("layout has no rows")
+	ErrEmptyRow       = errors.New("empty layout row")
+	ErrDuplicateKey   = errors.New("duplicate key in layout")
+	ErrUnmappedChar   = errors.New("alphabet character is not mapped")
+	ErrBadCoordinates = errors.New("coordinates do not match row keys")
+	ErrBadWidths      = errors.New("widths do not match row keys")
+	ErrBadLayer       = errors.New("layer does not match row keys")
+	ErrNoModifier     = errors.New("layer has no modifier key")
+)
+
+// noChar marks the keys which have no character on the Shift or AltGr layer.
+const noChar = ' '
+
+// Point is a key center on the keyboard in the key widths: X is horizontal, Y is vertical.
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Row is a single named row of keys. Keys are placed one key width apart starting
+// from the row offset, the wider keys from Widths push the next ones further.
+// Coordinates are optional, but if set, there must be exactly one coordinate per key
+// and the offset is ignored.
+// Shift and AltGr are the characters typed with the modifier on the same keys,
+// one per key, a space means the key has no character on the layer.
+// Fingers are the touch typing fingers for the keys, digits from '0' for the
+// left pinky to '9' for the right pinky.
+type Row struct {
+	Name        string    `json:"name,omitempty"`
+	Keys        string    `json:"keys"`
+	Shift       string    `json:"shift,omitempty"`
+	AltGr       string    `json:"altgr,omitempty"`
+	Fingers     string    `json:"fingers,omitempty"`
+	Offset      float64   `json:"offset,omitempty"`
+	Coordinates []Point   `json:"coordinates,omitempty"`
+	Widths      []float64 `json:"widths,omitempty"` // Key widths, 1 by default
+}
+
+// Modifiers are the positions of the modifier keys, e.g. the left and the right Shift.
+type Modifiers struct {
+	Shift []Point `json:"shift,omitempty"`
+	AltGr []Point `json:"altgr,omitempty"`
+}
+
+// Definition is a declarative keyboard layout, as it is stored in the layout files.
+type Definition struct {
+	Version   int               `json:"version"`
+	Name      string            `json:"name,omitempty"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	Alphabet  string            `json:"alphabet,omitempty"` // Characters which must be present on the keyboard
+	Rows      []Row             `json:"rows"`
+	Modifiers *Modifiers        `json:"modifiers,omitempty"`
+	Special   map[string]Point  `json:"special,omitempty"` // Positions of the enter, tab and space keys
+	Home      []Point           `json:"home,omitempty"`    // Finger home positions, required with the fingers
+	Effort    *Effort           `json:"effort,omitempty"`  // Keypress costs, none by default
+}
+
+// NewDefinition makes a definition from the plain layout rows.
+// Keys are placed on the integer grid by their row and column indexes.
+func NewDefinition(name string, layout Layout) *Definition {
+	rows := make([]Row, 0, len(layout))
+	for i := 0; i < len(layout); i++ {
+		rows = append(rows, Row{
+			Name:        "",
+			Keys:        layout[i],
+			Shift:       "",
+			AltGr:       "",
+			Fingers:     "",
+			Offset:      0,
+			Coordinates: nil,
+			Widths:      nil,
+		})
+	}
+
+	return &Definition{
+		Version:   DefinitionVersion,
+		Name:      name,
+		Metadata:  nil,
+		Alphabet:  "",
+		Rows:      rows,
+		Modifiers: nil,
+		Special:   nil,
+		Home:      nil,
+		Effort:    nil,
+	}
+}
+
+// WithShift returns a copy of the definition with the Shift layer rows and the Shift keys positions.
+func (d *Definition) WithShift(shift Layout, positions ...Point) *Definition {
+	clone := d.Clone()
+
+	for i := 0; i < len(clone.Rows) && i < len(shift); i++ {
+		clone.Rows[i].Shift = shift[i]
+	}
+
+	if clone.Modifiers == nil {
+		clone.Modifiers = &Modifiers{Shift: nil, AltGr: nil}
+	}
+
+	clone.Modifiers.Shift = append([]Point(nil), positions...)
+
+	return clone
+}
+
+// LoadLayout reads and validates the JSON layout definition from the file.
+func LoadLayout(path string) (*Definition, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, pkgerr.Wrapf(err, "failed open layout file '%s'", path)
+	}
+
+	defer f.Close()
+
+	def, err := ParseLayout(f)
+	if err != nil {
+		return nil, pkgerr.Wrapf(err, "failed load layout file '%s'", path)
+	}
+
+	return def, nil
+}
+
+// ParseLayout reads and validates the JSON layout definition.
+func ParseLayout(r io.Reader) (*Definition, error) {
+	var def Definition
+
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&def); err != nil {
+		return nil, pkgerr.Wrap(err, "failed decode layout")
+	}
+
+	if def.Alphabet == "" {
+		def.Alphabet = DefaultAlphabet
+	}
+
+	if err := def.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &def, nil
+}
+
+// WriteLayout encodes the layout definition as JSON accepted by ParseLayout.
+func WriteLayout(w io.Writer, def *Definition) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+
+	return pkgerr.Wrap(encoder.Encode(def), "failed encode layout")
+}
+
+// SaveLayout writes the layout definition to the JSON file.
+func SaveLayout(path string, def *Definition) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return pkgerr.Wrapf(err, "failed create layout file '%s'", path)
+	}
+
+	if err := WriteLayout(f, def); err != nil {
+		f.Close()
+		return pkgerr.Wrapf(err, "failed save layout file '%s'", path)
+	}
+
+	return pkgerr.Wrapf(f.Close(), "failed close layout file '%s'", path)
+}
+
+// Clone makes a deep copy of the definition.
+func (d *Definition) Clone() *Definition {
+	clone := *d
+
+	if d.Metadata != nil {
+		clone.Metadata = make(map[string]string, len(d.Metadata))
+		for k, v := range d.Metadata {
+			clone.Metadata[k] = v
+		}
+	}
+
+	if d.Modifiers != nil {
+		clone.Modifiers = &Modifiers{
+			Shift: append([]Point(nil), d.Modifiers.Shift...),
+			AltGr: append([]Point(nil), d.Modifiers.AltGr...),
+		}
+	}
+
+	if d.Special != nil {
+		clone.Special = make(map[string]Point, len(d.Special))
+		for name, position := range d.Special {
+			clone.Special[name] = position
+		}
+	}
+
+	if d.Home != nil {
+		clone.Home = append([]Point(nil), d.Home...)
+	}
+
+	if d.Effort != nil {
+		clone.Effort = d.Effort.clone()
+	}
+
+	clone.Rows = make([]Row, len(d.Rows))
+	for i := 0; i < len(d.Rows); i++ {
+		clone.Rows[i] = d.Rows[i]
+		if d.Rows[i].Coordinates != nil {
+			clone.Rows[i].Coordinates = append([]Point(nil), d.Rows[i].Coordinates...)
+		}
+
+		if d.Rows[i].Widths != nil {
+			clone.Rows[i].Widths = append([]float64(nil), d.Rows[i].Widths...)
+		}
+	}
+
+	return &clone
+}
+
+// Layout returns the plain rows of the definition.
+func (d *Definition) Layout() Layout {
+	layout := make(Layout, 0, len(d.Rows))
+	for i := 0; i < len(d.Rows); i++ {
+		layout = append(layout, d.Rows[i].Keys)
+	}
+
+	return layout
+}
+
+// Staggered returns a copy of the definition with the row offsets set, the finger homes
+// on the rows move with their keys. Extra offsets are ignored, missing ones leave the rows as is.
+func (d *Definition) Staggered(offsets ...float64) *Definition {
+	clone := d.Clone()
+
+	for i := 0; i < len(clone.Rows) && i < len(offsets); i++ {
+		shift := offsets[i] - clone.Rows[i].Offset
+		clone.Rows[i].Offset = offsets[i]
+
+		for finger := range clone.Home {
+			if clone.Home[finger].Y == float64(i) {
+				clone.Home[finger].X += shift
+			}
+		}
+	}
+
+	return clone
+}
+
+// Validate checks the definition is consistent: there are no empty rows,
+// no duplicate keys, every layer has its modifier and every alphabet character is mapped.
+func (d *Definition) Validate() error {
+	if d.Version != DefinitionVersion {
+		return pkgerr.Wrapf(ErrBadVersion, "version %d", d.Version)
+	}
+
+	if len(d.Rows) == 0 {
+		return ErrEmptyLayout
+	}
+
+	keys := make(map[rune]bool)
+
+	for i := 0; i < len(d.Rows); i++ {
+		row := &d.Rows[i]
+
+		if row.Keys == "" {
+			return pkgerr.Wrapf(ErrEmptyRow, "row %d '%s'", i, row.Name)
+		}
+
+		keysCount := utf8.RuneCountInString(row.Keys)
+
+		if row.Coordinates != nil && len(row.Coordinates) != keysCount {
+			return pkgerr.Wrapf(ErrBadCoordinates, "row %d '%s': %d keys, %d coordinates",
+				i, row.Name, keysCount, len(row.Coordinates))
+		}
+
+		if err := row.validateWidths(i, keysCount); err != nil {
+			return err
+		}
+
+		for layer := Base; layer < layersCount; layer++ {
+			chars := []rune(row.layer(layer))
+			if len(chars) == 0 {
+				continue
+			}
+
+			if layer != Base && len(chars) != keysCount {
+				return pkgerr.Wrapf(ErrBadLayer, "row %d '%s': %d keys, %d %s characters",
+					i, row.Name, keysCount, len(chars), layer)
+			}
+
+			if layer != Base && len(d.modifiers(layer)) == 0 && strings.Trim(string(chars), string(noChar)) != "" {
+				return pkgerr.Wrapf(ErrNoModifier, "%s in row %d '%s'", layer, i, row.Name)
+			}
+
+			for j := 0; j < len(chars); j++ {
+				if layer != Base && chars[j] == noChar {
+					continue
+				}
+
+				if keys[chars[j]] {
+					return pkgerr.Wrapf(ErrDuplicateKey, "'%c' in row %d '%s'", chars[j], i, row.Name)
+				}
+
+				keys[chars[j]] = true
+			}
+		}
+	}
+
+	for _, name := range d.specialNames() {
+		char, ok := specialKeys[name]
+		if !ok {
+			return pkgerr.Wrapf(ErrUnknownSpecialKey, "'%s'", name)
+		}
+
+		if keys[char] {
+			return pkgerr.Wrapf(ErrDuplicateKey, "special key '%s'", name)
+		}
+
+		keys[char] = true
+	}
+
+	if err := d.validateFingers(); err != nil {
+		return err
+	}
+
+	if err := d.validateEffort(keys); err != nil {
+		return err
+	}
+
+	var unmapped []rune
+
+	for _, char := range d.Alphabet {
+		if !keys[char] {
+			unmapped = append(unmapped, char)
+		}
+	}
+
+	if len(unmapped) != 0 {
+		return pkgerr.Wrapf(ErrUnmappedChar, "'%s'", string(unmapped))
+	}
+
+	return nil
+}
+
+// modifiers returns the positions of the modifier keys for the layer.
+func (d *Definition) modifiers(layer Layer) []Point {
+	if d.Modifiers == nil {
+		return nil
+	}
+
+	switch layer {
+	case Shift:
+		return d.Modifiers.Shift
+	case AltGr:
+		return d.Modifiers.AltGr
+	case Base, layersCount:
+	}
+
+	return nil
+}
+
+// layer returns the row characters on the layer.
+func (r *Row) layer(layer Layer) string {
+	switch layer {
+	case Base:
+		return r.Keys
+	case Shift:
+		return r.Shift
+	case AltGr:
+		return r.AltGr
+	case layersCount:
+	}
+
+	return ""
+}
+
+// position returns the coordinate of the j-th key in the i-th row.
+func (r *Row) position(i, j int) Point {
+	if r.Coordinates != nil {
+		return r.Coordinates[j]
+	}
+
+	if r.Widths == nil {
+		return Point{
+			X: r.Offset + float64(j),
+			Y: float64(i),
+		}
+	}
+
+	// The wider keys push the next ones, the centers are in the middle of the keys
+	left := r.Offset - 0.5 //nolint:gomnd // the left edge of the first key center
+	for k := 0; k < j; k++ {
+		left += r.Widths[k]
+	}
+
+	return Point{
+		X: left + r.Widths[j]/2,
+		Y: float64(i),
+	}
+}
+
+// width returns the width of the key in the key widths.
+func (r *Row) width(j int) float64 {
+	if r.Widths == nil {
+		return 1
+	}
+
+	return r.Widths[j]
+}
+
+func (r *Row) validateWidths(i, keysCount int) error {
+	if r.Widths == nil {
+		return nil
+	}
+
+	if len(r.Widths) != keysCount {
+		return pkgerr.Wrapf(ErrBadWidths, "row %d '%s': %d keys, %d widths", i, r.Name, keysCount, len(r.Widths))
+	}
+
+	for _, width := range r.Widths {
+		if width <= 0 {
+			return pkgerr.Wrapf(ErrBadWidths, "row %d '%s': width %v", i, r.Name, width)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,146 @@
+package keyboard
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func Test_LoadLayout(t *testing.T) {
+	t.Parallel()
+
+	def, err := LoadLayout("testdata/abc.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if def.Name != "abc" || len(def.Rows) != 3 {
+		t.Fatalf("Unexpected definition: %+v", def)
+	}
+
+	kbd, err := NewFromDefinition(def)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testData := []struct {
+		A, B     rune
+		Expected int
+	}{
+		{'a', 'c', 2},
+		{'a', 'j', 3}, // Explicit coordinates
+		{'a', 's', 2},
+		{'s', 'j', 1},
+	}
+
+	for _, testCase := range testData {
+		dist, err := kbd.GetDistance(testCase.A, testCase.B)
+		if err != nil {
+			t.Error(err)
+		}
+
+		if dist != testCase.Expected {
+			t.Errorf("Expected distance between '%s' and '%s': %d; got: %d",
+				string(testCase.A), string(testCase.B), testCase.Expected, dist)
+		}
+	}
+}
+
+func Test_ParseLayoutErrors(t *testing.T) {
+	t.Parallel()
+
+	testData := []struct {
+		Name     string
+		JSON     string
+		Expected error
+	}{
+		{"version", `{"version": 2, "rows": [{"keys": "a"}]}`, ErrBadVersion},
+		{"no rows", `{"version": 1, "rows": []}`, ErrEmptyLayout},
+		{"empty row", `{"version": 1, "alphabet": "a", "rows": [{"keys": "a"}, {"keys": ""}]}`, ErrEmptyRow},
+		{"duplicate", `{"version": 1, "alphabet": "ab", "rows": [{"keys": "ab"}, {"keys": "a"}]}`, ErrDuplicateKey},
+		{"unmapped", `{"version": 1, "rows": [{"keys": "qwerty"}]}`, ErrUnmappedChar},
+		{
+			"coordinates",
+			`{"version": 1, "alphabet": "ab", "rows": [{"keys": "ab", "coordinates": [{"x": 0, "y": 0}]}]}`,
+			ErrBadCoordinates,
+		},
+	}
+
+	for _, testCase := range testData {
+		_, err := ParseLayout(strings.NewReader(testCase.JSON))
+		if !errors.Is(err, testCase.Expected) {
+			t.Errorf("%s: expected error '%v', got '%v'", testCase.Name, testCase.Expected, err)
+		}
+	}
+}
+
+func Test_LoadLayoutQWERTY(t *testing.T) {
+	t.Parallel()
+
+	def, err := LoadLayout("../../../data/layouts/qwerty.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	layout := def.Layout()
+	expected := QWERTY()
+
+	if len(layout) != len(expected) {
+		t.Fatalf("Expected layout %v, got %v", expected, layout)
+	}
+
+	for i := 0; i < len(expected); i++ {
+		if layout[i] != expected[i] {
+			t.Errorf("Expected layout %v, got %v", expected, layout)
+		}
+	}
+}
+
+func Test_WriteLayout(t *testing.T) {
+	t.Parallel()
+
+	def, err := Lookup("azerty")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf strings.Builder
+	if err := WriteLayout(&buf, def.Staggered(StaggerANSI()...)); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := ParseLayout(strings.NewReader(buf.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded.Name != "azerty" || loaded.Rows[1].Offset != 0.5 || loaded.Rows[2].Shift != def.Rows[2].Shift {
+		t.Errorf("Unexpected definition: %+v", loaded)
+	}
+}
+
+func Test_ParseLayoutLayerErrors(t *testing.T) {
+	t.Parallel()
+
+	testData := []struct {
+		Name     string
+		JSON     string
+		Expected error
+	}{
+		{"layer length", `{"version": 1, "alphabet": "ab", "rows": [{"keys": "ab", "shift": "A"}]}`, ErrBadLayer},
+		{"no modifier", `{"version": 1, "alphabet": "ab", "rows": [{"keys": "ab", "shift": "AB"}]}`, ErrNoModifier},
+		{
+			"duplicate",
+			`{"version": 1, "alphabet": "ab", "rows": [{"keys": "ab", "altgr": " a"}],
+			  "modifiers": {"altgr": [{"x": 0, "y": 1}]}}`,
+			ErrDuplicateKey,
+		},
+	}
+
+	for _, testCase := range testData {
+		_, err := ParseLayout(strings.NewReader(testCase.JSON))
+		if !errors.Is(err, testCase.Expected) {
+			t.Errorf("%s: expected error '%v', got '%v'", testCase.Name, testCase.Expected, err)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package keyboard
+
+import (
+	"errors"
+	"math"
+
+	pkgerr "github.com/pkg/errors"
+)
+
+var ErrBadEffort = errors.New("bad key effort")
+
+// Effort is the cost of pressing the keys on top of the finger travel, in the key widths.
+// Every keypress costs Press plus the extra cost of the key, its row and its finger.
+// Reach is the cost per key width from the home position of the finger, it needs
+// the fingers. Repeat is added when the same key is pressed twice in a row.
+type Effort struct {
+	Press   float64            `json:"press,omitempty"`
+	Keys    map[string]float64 `json:"keys,omitempty"`    // By any character of the key or the special key name
+	Rows    []float64          `json:"rows,omitempty"`    // By the row index, the modifier and special keys have none
+	Fingers []float64          `json:"fingers,omitempty"` // From the left pinky to the right pinky
+	Reach   float64            `json:"reach,omitempty"`
+	Repeat  float64            `json:"repeat,omitempty"`
+}
+
+// clone makes a deep copy of the effort.
+func (e *Effort) clone() *Effort {
+	clone := *e
+
+	if e.Keys != nil {
+		clone.Keys = make(map[string]float64, len(e.Keys))
+		for name, cost := range e.Keys {
+			clone.Keys[name] = cost
+		}
+	}
+
+	clone.Rows = append([]float64(nil), e.Rows...)
+	clone.Fingers = append([]float64(nil), e.Fingers...)
+
+	return &clone
+}
+
+// validateEffort checks the costs are not negative and the keys, rows and fingers exist.
+func (d *Definition) validateEffort(keys map[rune]bool) error {
+	e := d.Effort
+	if e == nil {
+		return nil
+	}
+
+	costs := append([]float64{e.Press, e.Reach, e.Repeat}, e.Rows...)
+	costs = append(costs, e.Fingers...)
+
+	for name, cost := range e.Keys {
+		char, err := ParseKey(name)
+		if err != nil || !keys[char] {
+			return pkgerr.Wrapf(ErrBadEffort, "key '%s' is not on the layout", name)
+		}
+
+		costs = append(costs, cost)
+	}
+
+	for _, cost := range costs {
+		if cost < 0 || math.IsNaN(cost) || math.IsInf(cost, 0) {
+			return pkgerr.Wrapf(ErrBadEffort, "cost %v", cost)
+		}
+	}
+
+	if len(e.Rows) > len(d.Rows) {
+		return pkgerr.Wrapf(ErrBadEffort, "%d row costs for %d rows", len(e.Rows), len(d.Rows))
+	}
+
+	if e.Fingers != nil && len(e.Fingers) != int(FingersCount) {
+		return pkgerr.Wrapf(ErrBadEffort, "%d finger costs, expected %d", len(e.Fingers), FingersCount)
+	}
+
+	if (e.Fingers != nil || e.Reach != 0) && len(d.Home) == 0 {
+		return pkgerr.Wrap(ErrBadEffort, "finger and reach costs need the fingers")
+	}
+
+	return nil
+}
+
+// setEffort computes the keypress cost of every slot. The key costs are set after all the
+// characters are mapped, so the key may be named by any of its characters.
+func (k *Keyboard) setEffort(def *Definition, slotRows []int) {
+	k.effort = make([]float64, len(k.slots))
+
+	e := def.Effort
+	if e == nil {
+		return
+	}
+
+	k.repeat = e.Repeat
+
+	for slot := 0; slot < len(k.slots); slot++ {
+		if k.hasHome && slot >= k.home[LeftPinky] {
+			continue // The home positions are not keys
+		}
+
+		cost := e.Press
+
+		if row := slotRows[slot]; row >= 0 && row < len(e.Rows) {
+			cost += e.Rows[row]
+		}
+
+		if k.hasHome {
+			finger := k.fingers[slot]
+			if e.Fingers != nil {
+				cost += e.Fingers[finger]
+			}
+
+			home := k.slots[k.home[finger]]
+			cost += e.Reach * math.Hypot(k.slots[slot].X-home.X, k.slots[slot].Y-home.Y)
+		}
+
+		k.effort[slot] = cost
+	}
+
+	for name, cost := range e.Keys {
+		char, _ := ParseKey(name)
+		if key, ok := k.lookup(char); ok {
+			k.effort[key.slot] += cost
+		}
+	}
+}
+
+// KeyCost returns the cost of typing the character first, in the key widths. There is no travel
+// to the first key pressed, the characters on the other layers pay the travel from the cheapest
+// modifier to the key and both keypresses, as they do in the middle of the text.
+func (k *Keyboard) KeyCost(char rune) (float64, error) {
+	key, ok := k.lookup(char)
+	if !ok {
+		return 0, &UnknownKeyError{Key: char}
+	}
+
+	if key.layer == Base {
+		return k.effort[key.slot], nil
+	}
+
+	best := -1.0
+
+	for _, modifier := range k.modifiers[key.layer] {
+		fromModifier := k.dist[modifier*len(k.slots)+key.slot]
+		if fromModifier < 0 {
+			continue
+		}
+
+		cost := k.effort[modifier] + fromModifier + k.pressCost(modifier, key.slot)
+		if best < 0 || cost < best {
+			best = cost
+		}
+	}
+
+	if best < 0 {
+		return 0, pkgerr.Wrapf(ErrUnreachable, "'%c' through %s", char, key.layer)
+	}
+
+	return best, nil
+}
+
+// GetKeyCost returns the cost of typing the character first in the resolution units.
+func (k *Keyboard) GetKeyCost(char rune) (int, error) {
+	cost, err := k.KeyCost(char)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(math.Round(cost * k.resolution)), nil
+}
+
+// pressCost is the effort of pressing the slot right after the previous one.
+func (k *Keyboard) pressCost(prev, slot int) float64 {
+	if prev == slot {
+		return k.effort[slot] + k.repeat
+	}
+
+	return k.effort[slot]
+}
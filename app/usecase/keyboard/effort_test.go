@@ -0,0 +1,134 @@
+package keyboard
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func newEffortQWERTY(t *testing.T, effort *Effort) *Keyboard {
+	t.Helper()
+
+	def, err := Lookup("qwerty")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	def = def.Clone()
+	def.Effort = effort
+
+	kbd, err := NewFromDefinition(def)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return kbd
+}
+
+func Test_Effort(t *testing.T) {
+	t.Parallel()
+
+	kbd := newEffortQWERTY(t, &Effort{
+		Press:   0.5,
+		Keys:    map[string]float64{"space": 1},
+		Rows:    []float64{0, 0.25},
+		Fingers: []float64{1, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		Reach:   0,
+		Repeat:  2,
+	})
+
+	testData := []struct {
+		A, B     rune
+		Expected float64
+	}{
+		{'f', 'f', 2.5}, // Press and repeat
+		{'f', 'j', 3.5},
+		{'f', 'a', 4.5},  // Pinky
+		{'f', 'r', 1.75}, // Top row
+		{'f', ' ', 5},    // Space key cost
+	}
+
+	for _, testCase := range testData {
+		dist, err := kbd.Distance(testCase.A, testCase.B)
+		if err != nil {
+			t.Error(err)
+		}
+
+		if dist != testCase.Expected {
+			t.Errorf("Expected distance from '%c' to '%c': %v; got: %v", testCase.A, testCase.B, testCase.Expected, dist)
+		}
+	}
+
+	// The left Shift is pressed by the pinky and is 5 keys away from 'f'
+	if cost, err := kbd.KeyCost('F'); err != nil || cost != 7 {
+		t.Errorf("Expected 'F' key cost with the pinky Shift: 7; got: %v, %v", cost, err)
+	}
+
+	touch, err := NewTouchTyping(kbd, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if dist, err := touch.GetSequenceDistance("ff"); err != nil || dist != 3 {
+		t.Errorf("Expected touch typing cost with the repeat: 3; got: %d, %v", dist, err)
+	}
+}
+
+func Test_EffortReach(t *testing.T) {
+	t.Parallel()
+
+	kbd := newEffortQWERTY(t, &Effort{
+		Press:   0,
+		Keys:    nil,
+		Rows:    nil,
+		Fingers: nil,
+		Reach:   1,
+		Repeat:  0,
+	})
+
+	// 't' is one row up and one column right of the left index home key 'f'
+	dist, err := kbd.Distance('f', 't')
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := 2 + math.Sqrt2; math.Abs(dist-expected) > 1e-9 {
+		t.Errorf("Expected distance: %v; got: %v", expected, dist)
+	}
+
+	if cost, err := kbd.KeyCost('f'); err != nil || cost != 0 {
+		t.Errorf("Expected no home key cost; got: %v, %v", cost, err)
+	}
+}
+
+func Test_EffortErrors(t *testing.T) {
+	t.Parallel()
+
+	testData := []*Effort{
+		{Press: -1, Keys: nil, Rows: nil, Fingers: nil, Reach: 0, Repeat: 0},
+		{Press: 0, Keys: map[string]float64{"ж": 1}, Rows: nil, Fingers: nil, Reach: 0, Repeat: 0},
+		{Press: 0, Keys: nil, Rows: []float64{0, 0, 0, 0, 0}, Fingers: nil, Reach: 0, Repeat: 0},
+		{Press: 0, Keys: nil, Rows: nil, Fingers: []float64{1, 2, 3}, Reach: 0, Repeat: 0},
+	}
+
+	for _, effort := range testData {
+		def, err := Lookup("qwerty")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		def = def.Clone()
+		def.Effort = effort
+
+		if err := def.Validate(); !errors.Is(err, ErrBadEffort) {
+			t.Errorf("Expected bad effort error for %+v; got: %v", effort, err)
+		}
+	}
+
+	def := NewDefinition("", Layout{"abc"})
+	def.Effort = &Effort{Press: 0, Keys: nil, Rows: nil, Fingers: nil, Reach: 1, Repeat: 0}
+
+	if err := def.Validate(); !errors.Is(err, ErrBadEffort) {
+		t.Errorf("Expected reach without fingers error; got: %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package keyboard
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	pkgerr "github.com/pkg/errors"
+)
+
+// Finger is the finger which presses the key, counted from the left pinky.
+type Finger int
+
+const (
+	LeftPinky Finger = iota
+	LeftRing
+	LeftMiddle
+	LeftIndex
+	LeftThumb
+	RightThumb
+	RightIndex
+	RightMiddle
+	RightRing
+	RightPinky
+
+	FingersCount
+)
+
+// homeRow is the row index of the home row on the built-in layouts.
+const homeRow = 2
+
+var ErrBadFingers = errors.New("bad finger assignment")
+
+// standardFingers is the touch typing finger for each grid column of the main rows.
+var standardFingers = []Finger{
+	LeftPinky, LeftRing, LeftMiddle, LeftIndex, LeftIndex,
+	RightIndex, RightIndex, RightMiddle, RightRing, RightPinky,
+}
+
+// columnFinger returns the touch typing finger for the grid column, the outer columns
+// are pressed by the pinkies.
+func columnFinger(column int) Finger {
+	if column < 0 {
+		return LeftPinky
+	}
+
+	if column >= len(standardFingers) {
+		return RightPinky
+	}
+
+	return standardFingers[column]
+}
+
+// fingerOf returns the finger from the row's finger string, e.g. '0' is the left pinky.
+func fingerOf(char rune) Finger {
+	return Finger(char - '0')
+}
+
+// WithStandardFingers returns a copy of the definition with the touch typing finger
+// assignment by the key columns on the grid, counted from the first key of the number row,
+// and the home positions on the home row. The thumbs rest below the bottom row.
+func (d *Definition) WithStandardFingers() *Definition {
+	clone := d.Clone()
+
+	for i := 0; i < len(clone.Rows); i++ {
+		row := &clone.Rows[i]
+
+		var fingers strings.Builder
+
+		for j := 0; j < utf8.RuneCountInString(row.Keys); j++ {
+			column := int(math.Floor(row.position(i, j).X))
+			fingers.WriteRune('0' + rune(columnFinger(column)))
+		}
+
+		row.Fingers = fingers.String()
+	}
+
+	clone.Home = make([]Point, FingersCount)
+
+	for finger := LeftPinky; finger < FingersCount; finger++ {
+		clone.Home[finger] = Point{X: float64(finger), Y: homeRow}
+
+		if finger == LeftThumb || finger == RightThumb {
+			clone.Home[finger].Y = homeRow + 2 //nolint:gomnd // below the bottom row
+		}
+	}
+
+	return clone
+}
+
+// validateFingers checks the fingers are set for all keys of the row,
+// if they are set at all, and the home positions are set for all fingers.
+func (d *Definition) validateFingers() error {
+	hasFingers := false
+
+	for i := 0; i < len(d.Rows); i++ {
+		row := &d.Rows[i]
+		if row.Fingers == "" {
+			continue
+		}
+
+		hasFingers = true
+
+		if utf8.RuneCountInString(row.Fingers) != utf8.RuneCountInString(row.Keys) {
+			return pkgerr.Wrapf(ErrBadFingers, "row %d '%s': fingers do not match keys", i, row.Name)
+		}
+
+		for _, char := range row.Fingers {
+			if finger := fingerOf(char); finger < LeftPinky || finger >= FingersCount {
+				return pkgerr.Wrapf(ErrBadFingers, "row %d '%s': bad finger '%c'", i, row.Name, char)
+			}
+		}
+	}
+
+	if hasFingers && len(d.Home) != int(FingersCount) {
+		return pkgerr.Wrapf(ErrBadFingers, "%d home positions, expected %d", len(d.Home), FingersCount)
+	}
+
+	return nil
+}
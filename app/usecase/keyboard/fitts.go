@@ -0,0 +1,120 @@
+package keyboard
+
+import (
+	"errors"
+	"math"
+
+	pkgerr "github.com/pkg/errors"
+)
+
+// Fitts' law constants for tapping the keys, from the soft keyboard studies by Soukoreff and MacKenzie.
+const (
+	DefaultFittsA = 176 // Milliseconds per keystroke
+	DefaultFittsB = 64  // Milliseconds per bit of the movement difficulty
+)
+
+var ErrBadFitts = errors.New("negative Fitts' law constant")
+
+// Fitts is the time cost model. Moving the finger between the keys takes
+// MT = A + B * log2(D/W + 1) milliseconds, where D is the straight distance between the
+// key centers and W is the size of the target key along the movement, so one long move
+// is faster than several short ones over the same distance. The keyboard metric is not used.
+type Fitts struct {
+	kbd  *Keyboard
+	a, b float64
+}
+
+func NewFitts(kbd *Keyboard, a, b float64) (*Fitts, error) {
+	if a < 0 || b < 0 {
+		return nil, pkgerr.Wrapf(ErrBadFitts, "a=%v, b=%v", a, b)
+	}
+
+	return &Fitts{
+		kbd: kbd,
+		a:   a,
+		b:   b,
+	}, nil
+}
+
+// IsMapped reports whether the character is on the layout.
+func (f *Fitts) IsMapped(char rune) bool {
+	return f.kbd.IsMapped(char)
+}
+
+// Name returns the layout name.
+func (f *Fitts) Name() string {
+	return f.kbd.Name()
+}
+
+// Locate returns the position of the character key on the keyboard.
+func (f *Fitts) Locate(char rune) (x, y float64, ok bool) {
+	return f.kbd.Locate(char)
+}
+
+// GetDistance returns the time of typing b after a in the whole milliseconds.
+func (f *Fitts) GetDistance(a, b rune) (int, error) {
+	ms, err := f.Time(a, b)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(math.Round(ms)), nil
+}
+
+// Time returns the time of typing b after a in milliseconds. The character on a modifier
+// layer takes two movements: to the nearest modifier key and then to the character key.
+func (f *Fitts) Time(a, b rune) (float64, error) {
+	from, ok := f.kbd.lookup(a)
+	if !ok {
+		return 0, &UnknownKeyError{Key: a}
+	}
+
+	to, ok := f.kbd.lookup(b)
+	if !ok {
+		return 0, &UnknownKeyError{Key: b}
+	}
+
+	if to.layer == Base {
+		return f.move(from.slot, to.slot), nil
+	}
+
+	best := math.Inf(1)
+
+	for _, modifier := range f.kbd.modifiers[to.layer] {
+		best = math.Min(best, f.move(from.slot, modifier)+f.move(modifier, to.slot))
+	}
+
+	if math.IsInf(best, 1) {
+		return 0, pkgerr.Wrapf(ErrUnreachable, "from '%c' to '%c' through %s", a, b, to.layer)
+	}
+
+	return best, nil
+}
+
+func (f *Fitts) move(from, to int) float64 {
+	dx := f.kbd.slots[to].X - f.kbd.slots[from].X
+	dy := f.kbd.slots[to].Y - f.kbd.slots[from].Y
+
+	dist := math.Hypot(dx, dy)
+	if dist == 0 {
+		return f.a
+	}
+
+	return f.a + f.b*math.Log2(dist/approachWidth(f.kbd.widths[to], dx/dist, dy/dist)+1)
+}
+
+// approachWidth is the length of the key cut by the movement line through its center:
+// the key width for the horizontal moves, the key height for the vertical ones.
+func approachWidth(width, cos, sin float64) float64 {
+	size := math.Inf(1)
+
+	if cos != 0 {
+		size = width / math.Abs(cos)
+	}
+
+	if sin != 0 {
+		size = math.Min(size, 1/math.Abs(sin))
+	}
+
+	return size
+}
@@ -0,0 +1,79 @@
+package keyboard
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_Fitts(t *testing.T) {
+	t.Parallel()
+
+	kbd, err := NewQWERTY()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fitts, err := NewFitts(kbd, DefaultFittsA, DefaultFittsB)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testData := []struct {
+		A, B     rune
+		Expected int
+	}{
+		{'a', 'a', 176},
+		{'a', 's', 240},
+		{'a', 'l', 379}, // Faster than 8 moves by one key
+		{'s', 'S', 555}, // Through the left Shift
+	}
+
+	for _, testCase := range testData {
+		ms, err := fitts.GetDistance(testCase.A, testCase.B)
+		if err != nil {
+			t.Error(err)
+		}
+
+		if ms != testCase.Expected {
+			t.Errorf("Expected time from '%c' to '%c': %d; got: %d", testCase.A, testCase.B, testCase.Expected, ms)
+		}
+	}
+
+	if _, err := fitts.GetDistance('a', 'ж'); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Expected unknown key error; got: %v", err)
+	}
+
+	if _, err := NewFitts(kbd, -1, 0); !errors.Is(err, ErrBadFitts) {
+		t.Errorf("Expected bad constants error; got: %v", err)
+	}
+}
+
+func Test_FittsWideKey(t *testing.T) {
+	t.Parallel()
+
+	def := NewDefinition("", Layout{"ab"})
+	def.Rows[0].Widths = []float64{1, 3}
+
+	kbd, err := NewFromDefinition(def)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if x, _, _ := kbd.Locate('b'); x != 2 {
+		t.Errorf("Expected the wide key center at 2; got: %v", x)
+	}
+
+	fitts, err := NewFitts(kbd, DefaultFittsA, DefaultFittsB)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ms, err := fitts.GetDistance('a', 'b'); err != nil || ms != 223 {
+		t.Errorf("Expected 223 ms to the wide key; got: %d, %v", ms, err)
+	}
+
+	def.Rows[0].Widths = []float64{1}
+	if _, err := NewFromDefinition(def); !errors.Is(err, ErrBadWidths) {
+		t.Errorf("Expected bad widths error; got: %v", err)
+	}
+}
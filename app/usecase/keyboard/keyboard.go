@@ -1,52 +1,407 @@
 package keyboard
 
 import (
+	"errors"
+	"fmt"
 	"math"
+	"sort"
+
+	pkgerr "github.com/pkg/errors"
+)
+
+var (
+	ErrBadResolution = errors.New("resolution must be positive")
+	ErrUnreachable   = errors.New("key is unreachable by hops")
+	ErrUnknownKey    = errors.New("key is not on the layout")
+)
+
+const (
+	denseChars = 256 // Characters with the smaller codes are looked up in the dense table
+	bitmapWord = 64
+)
+
+// Layer is the set of characters typed with the same modifier.
+type Layer int
+
+const (
+	Base  Layer = iota // No modifier
+	Shift              // Typed with Shift held
+	AltGr              // Typed with AltGr held
+
+	layersCount
 )
 
-type coordinate struct {
-	x, y int
+func (l Layer) String() string {
+	switch l {
+	case Base:
+		return "base"
+	case Shift:
+		return "shift"
+	case AltGr:
+		return "altgr"
+	case layersCount:
+	}
+
+	return "unknown"
+}
+
+// UnknownKeyError is returned for the characters which are not on the layout.
+// It matches ErrUnknownKey with errors.Is.
+type UnknownKeyError struct {
+	Key rune
+}
+
+func (e *UnknownKeyError) Error() string {
+	return fmt.Sprintf("key '%c' (%U) is not on the layout", e.Key, e.Key)
+}
+
+func (e *UnknownKeyError) Is(target error) bool {
+	return target == ErrUnknownKey
+}
+
+// charKey is the key and the layer the character is typed with.
+type charKey struct {
+	slot  int
+	layer Layer
 }
 
 type Keyboard struct {
-	coordinates []coordinate
+	name string
+
+	// slots are the positions of the physical keys, the modifier keys included
+	slots     []Point
+	widths    []float64 // Key width for each slot
+	dense     [denseChars]charKey
+	mapped    [denseChars / bitmapWord]uint64
+	sparse    map[rune]charKey
+	modifiers [layersCount][]int // Slots of the modifier keys for each layer
+	dist      []float64          // slots x slots distances, negative if unreachable
+	effort    []float64          // Keypress cost for each slot
+	repeat    float64            // Extra cost of pressing the same key again
+
+	// Touch typing, only if the layout has the fingers
+	fingers []Finger          // Finger for each slot
+	home    [FingersCount]int // Slots of the finger home positions
+	hasHome bool
+
+	metric     Metric
+	resolution float64
+}
+
+// Option tunes the keyboard geometry.
+type Option func(k *Keyboard)
+
+// WithMetric sets the distance metric, Manhattan is used by default.
+func WithMetric(metric Metric) Option {
+	return func(k *Keyboard) {
+		k.metric = metric
+	}
+}
+
+// WithResolution sets how many distance units make one key width. GetDistance returns
+// the distance rounded to the integer units, so fractional metrics need the resolution
+// greater than 1 to be meaningful.
+func WithResolution(resolution int) Option {
+	return func(k *Keyboard) {
+		k.resolution = float64(resolution)
+	}
+}
+
+// NewQWERTY makes the built-in QWERTY keyboard.
+func NewQWERTY(opts ...Option) (*Keyboard, error) {
+	return NewByName("qwerty", opts...)
+}
+
+func New(layout Layout, opts ...Option) (*Keyboard, error) {
+	return NewFromDefinition(NewDefinition("", layout), opts...)
+}
+
+// NewFromDefinition makes the keyboard from the declarative layout definition.
+func NewFromDefinition(def *Definition, opts ...Option) (*Keyboard, error) {
+	if err := def.Validate(); err != nil {
+		return nil, pkgerr.Wrapf(err, "bad layout '%s'", def.Name)
+	}
+
+	kbd := &Keyboard{
+		name: def.Name,
+
+		slots:     nil,
+		widths:    nil,
+		dense:     [denseChars]charKey{},
+		mapped:    [denseChars / bitmapWord]uint64{},
+		sparse:    make(map[rune]charKey),
+		modifiers: [layersCount][]int{},
+		dist:      nil,
+		effort:    nil,
+		repeat:    0,
+
+		fingers: nil,
+		home:    [FingersCount]int{},
+		hasHome: false,
+
+		metric:     Manhattan,
+		resolution: 1,
+	}
+
+	for _, opt := range opts {
+		opt(kbd)
+	}
+
+	if kbd.resolution <= 0 {
+		return nil, pkgerr.Wrapf(ErrBadResolution, "%v", kbd.resolution)
+	}
+
+	var slotRows []int // Row index for each slot, -1 for the keys out of the rows
+
+	for i := 0; i < len(def.Rows); i++ {
+		row := &def.Rows[i]
+
+		var layers [layersCount][]rune
+		for layer := Base; layer < layersCount; layer++ {
+			layers[layer] = []rune(row.layer(layer))
+		}
+
+		fingers := []rune(row.Fingers)
+
+		for j := 0; j < len(layers[Base]); j++ {
+			slot := len(kbd.slots)
+			kbd.slots = append(kbd.slots, row.position(i, j))
+			kbd.widths = append(kbd.widths, row.width(j))
+			slotRows = append(slotRows, i)
+
+			finger := columnFinger(j)
+			if len(fingers) != 0 {
+				finger = fingerOf(fingers[j])
+			}
+
+			kbd.fingers = append(kbd.fingers, finger)
+
+			for layer := Base; layer < layersCount; layer++ {
+				chars := layers[layer]
+				if len(chars) == 0 || (layer != Base && chars[j] == noChar) {
+					continue
+				}
+
+				kbd.setChar(chars[j], charKey{slot: slot, layer: layer})
+			}
+		}
+	}
+
+	for _, name := range def.specialNames() {
+		position := def.Special[name]
+
+		kbd.setChar(specialKeys[name], charKey{slot: len(kbd.slots), layer: Base})
+		kbd.slots = append(kbd.slots, position)
+		kbd.widths = append(kbd.widths, 1)
+		slotRows = append(slotRows, -1)
+		kbd.fingers = append(kbd.fingers, specialFinger(name, position))
+	}
+
+	for layer := Shift; layer < layersCount; layer++ {
+		for _, position := range def.modifiers(layer) {
+			kbd.modifiers[layer] = append(kbd.modifiers[layer], len(kbd.slots))
+			kbd.slots = append(kbd.slots, position)
+			kbd.widths = append(kbd.widths, 1)
+			slotRows = append(slotRows, -1)
+			kbd.fingers = append(kbd.fingers, columnFinger(int(math.Round(position.X))))
+		}
+	}
+
+	if len(def.Home) != 0 {
+		kbd.hasHome = true
+
+		for finger := LeftPinky; finger < FingersCount; finger++ {
+			kbd.home[finger] = len(kbd.slots)
+			kbd.slots = append(kbd.slots, def.Home[finger])
+			kbd.widths = append(kbd.widths, 1)
+			slotRows = append(slotRows, -1)
+			kbd.fingers = append(kbd.fingers, finger)
+		}
+	}
+
+	kbd.dist = calcSlotDistances(kbd.slots, kbd.metric)
+	kbd.setEffort(def, slotRows)
+
+	return kbd, nil
+}
+
+// IsMapped reports whether the character is on the layout.
+func (k *Keyboard) IsMapped(char rune) bool {
+	_, ok := k.lookup(char)
+
+	return ok
+}
+
+// Chars returns all the characters on the layout, sorted.
+func (k *Keyboard) Chars() []rune {
+	chars := make([]rune, 0, len(k.sparse)+denseChars)
+
+	for char := rune(0); char < denseChars; char++ {
+		if _, ok := k.lookup(char); ok {
+			chars = append(chars, char)
+		}
+	}
+
+	for char := range k.sparse {
+		chars = append(chars, char)
+	}
+
+	sort.Slice(chars, func(i, j int) bool { return chars[i] < chars[j] })
+
+	return chars
+}
+
+// Locate returns the position of the character key in the key widths.
+func (k *Keyboard) Locate(char rune) (x, y float64, ok bool) {
+	key, ok := k.lookup(char)
+	if !ok {
+		return 0, 0, false
+	}
+
+	return k.slots[key.slot].X, k.slots[key.slot].Y, true
+}
+
+// Name returns the layout name, empty if the layout has none.
+func (k *Keyboard) Name() string {
+	return k.name
 }
 
-func NewQWERTY() (*Keyboard, error) {
-	return New(QWERTY())
+// GetDistance returns the distance between the keys in the resolution units.
+func (k *Keyboard) GetDistance(a, b rune) (int, error) {
+	dist, err := k.Distance(a, b)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(math.Round(dist * k.resolution)), nil
 }
 
-func New(layout Layout) (*Keyboard, error) {
-	const maxChar = ^byte(0)
-	coordinates := make([]coordinate, maxChar)
+// Distance returns the distance between the keys in the key widths.
+// If the second character is typed with a modifier, the finger travels to the
+// modifier key first and then to the character key. The keypress effort of the
+// layout is added for every key pressed on the way.
+// UnknownKeyError is returned if any of the keys is not on the layout.
+func (k *Keyboard) Distance(a, b rune) (float64, error) {
+	fromKey, ok := k.lookup(a)
+	if !ok {
+		return 0, &UnknownKeyError{Key: a}
+	}
+
+	to, ok := k.lookup(b)
+	if !ok {
+		return 0, &UnknownKeyError{Key: b}
+	}
+
+	from := fromKey.slot
+
+	if to.layer == Base {
+		dist, err := k.slotDistance(from, to.slot, a, b)
+		if err != nil {
+			return 0, err
+		}
+
+		return dist + k.pressCost(from, to.slot), nil
+	}
+
+	best := -1.0
 
-	for i := 0; i < len(layout); i++ {
-		for j := 0; j < len(layout[i]); j++ {
-			char := layout[i][j]
+	for _, modifier := range k.modifiers[to.layer] {
+		toModifier := k.dist[from*len(k.slots)+modifier]
+		fromModifier := k.dist[modifier*len(k.slots)+to.slot]
 
-			idx := getIdx(char)
+		if toModifier < 0 || fromModifier < 0 {
+			continue
+		}
 
-			coordinates[idx] = coordinate{i, j}
+		dist := toModifier + fromModifier + k.pressCost(from, modifier) + k.pressCost(modifier, to.slot)
+		if best < 0 || dist < best {
+			best = dist
 		}
 	}
 
-	return &Keyboard{
-		coordinates: coordinates,
-	}, nil
+	if best < 0 {
+		return 0, pkgerr.Wrapf(ErrUnreachable, "from '%c' to '%c' through %s", a, b, to.layer)
+	}
+
+	return best, nil
+}
+
+func (k *Keyboard) slotDistance(from, to int, a, b rune) (float64, error) {
+	dist := k.dist[from*len(k.slots)+to]
+	if dist < 0 {
+		return 0, pkgerr.Wrapf(ErrUnreachable, "from '%c' to '%c'", a, b)
+	}
+
+	return dist, nil
+}
+
+func (k *Keyboard) lookup(char rune) (charKey, bool) {
+	if char >= 0 && char < denseChars {
+		return k.dense[char], k.mapped[char/bitmapWord]&(1<<(char%bitmapWord)) != 0
+	}
+
+	key, ok := k.sparse[char]
+
+	return key, ok
+}
+
+func (k *Keyboard) setChar(char rune, key charKey) {
+	if char >= 0 && char < denseChars {
+		k.dense[char] = key
+		k.mapped[char/bitmapWord] |= 1 << (char % bitmapWord)
+
+		return
+	}
+
+	k.sparse[char] = key
 }
 
-func (k *Keyboard) GetDistance(a, b byte) (int, error) {
-	aIdx := getIdx(a)
+// calcSlotDistances measures the distances between all the keys.
+func calcSlotDistances(slots []Point, metric Metric) []float64 {
+	if metric == KeyHops {
+		return calcHops(slots)
+	}
 
-	bIdx := getIdx(b)
+	dist := make([]float64, len(slots)*len(slots))
 
-	aCoord := &k.coordinates[aIdx]
-	bCoord := &k.coordinates[bIdx]
+	for i := 0; i < len(slots); i++ {
+		for j := 0; j < len(slots); j++ {
+			dist[i*len(slots)+j] = metric.measure(slots[i], slots[j])
+		}
+	}
 
-	// A bit overhead for int -> float64 -> int conversions, may be fixed by custom Abs function
-	return int(math.Abs(float64(aCoord.x-bCoord.x)) + math.Abs(float64(aCoord.y-bCoord.y))), nil
+	return dist
 }
 
-func getIdx(char byte) int {
-	return int(char)
+// calcHops finds the shortest hop paths between all the keys with BFS.
+func calcHops(slots []Point) []float64 {
+	hops := make([]float64, len(slots)*len(slots))
+	for i := range hops {
+		hops[i] = -1
+	}
+
+	queue := make([]int, 0, len(slots))
+
+	for from := 0; from < len(slots); from++ {
+		dist := hops[from*len(slots) : (from+1)*len(slots)]
+		dist[from] = 0
+
+		queue = append(queue[:0], from)
+
+		for len(queue) != 0 {
+			current := queue[0]
+			queue = queue[1:]
+
+			for next := 0; next < len(slots); next++ {
+				if dist[next] >= 0 || !adjacent(slots[current], slots[next]) {
+					continue
+				}
+
+				dist[next] = dist[current] + 1
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	return hops
 }
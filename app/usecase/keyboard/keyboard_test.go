@@ -1,6 +1,9 @@
 package keyboard
 
-import "testing"
+import (
+	"errors"
+	"testing"
+)
 
 func Test_QWERTY(t *testing.T) {
 	t.Parallel()
@@ -11,7 +14,7 @@ func Test_QWERTY(t *testing.T) {
 	}
 
 	testData := []struct {
-		A, B     byte
+		A, B     rune
 		Expected int
 	}{
 		{'s', 'a', 1},
@@ -38,3 +41,253 @@ func Test_QWERTY(t *testing.T) {
 		}
 	}
 }
+
+func Test_StaggeredMetrics(t *testing.T) {
+	t.Parallel()
+
+	def := NewDefinition("qwerty", QWERTY()).Staggered(StaggerANSI()...)
+
+	testData := []struct {
+		Metric   Metric
+		A, B     rune
+		Expected int
+	}{
+		{Manhattan, 't', 'b', 275},
+		{Manhattan, 'q', 'a', 125},
+		{Euclidean, 't', 'b', 214},
+		{Euclidean, 's', 'a', 100},
+		{Chebyshev, 't', 'b', 200},
+		{Chebyshev, 'q', 'c', 275},
+		{KeyHops, 't', 'b', 200},
+		{KeyHops, 'r', 'd', 100},
+		{KeyHops, 'r', 'g', 200},
+		{KeyHops, 'a', 'l', 800},
+		{KeyHops, 'q', 'm', 800},
+	}
+
+	for _, testCase := range testData {
+		kbd, err := NewFromDefinition(def, WithMetric(testCase.Metric), WithResolution(100))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		dist, err := kbd.GetDistance(testCase.A, testCase.B)
+		if err != nil {
+			t.Error(err)
+		}
+
+		if dist != testCase.Expected {
+			t.Errorf("Expected %s distance between '%s' and '%s': %d; got: %d",
+				testCase.Metric,
+				string(testCase.A),
+				string(testCase.B),
+				testCase.Expected,
+				dist)
+		}
+	}
+}
+
+func Test_KeyHopsGrid(t *testing.T) {
+	t.Parallel()
+
+	// Without the stagger only the straight moves are hops, so it is the same as Manhattan
+	hops, err := NewQWERTY(WithMetric(KeyHops))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manhattan, err := NewQWERTY()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, a := range []rune("qazwsx") {
+		for _, b := range []rune("plmokn") {
+			expected, _ := manhattan.GetDistance(a, b)
+
+			dist, err := hops.GetDistance(a, b)
+			if err != nil {
+				t.Error(err)
+			}
+
+			if dist != expected {
+				t.Errorf("Expected hops between '%s' and '%s': %d; got: %d", string(a), string(b), expected, dist)
+			}
+		}
+	}
+}
+
+func Test_ParseMetric(t *testing.T) {
+	t.Parallel()
+
+	for _, metric := range []Metric{Manhattan, Euclidean, Chebyshev, KeyHops} {
+		got, err := ParseMetric(metric.String())
+		if err != nil || got != metric {
+			t.Errorf("Expected metric %s, got %s (%v)", metric, got, err)
+		}
+	}
+
+	if _, err := ParseMetric("taxicab"); err == nil {
+		t.Error("Expected error for unknown metric")
+	}
+
+	if _, err := NewQWERTY(WithResolution(0)); err == nil {
+		t.Error("Expected error for zero resolution")
+	}
+}
+
+func Test_UnknownKey(t *testing.T) {
+	t.Parallel()
+
+	kbd, err := NewQWERTY()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !kbd.IsMapped('q') || !kbd.IsMapped('1') {
+		t.Error("Expected 'q' and '1' to be mapped")
+	}
+
+	for _, char := range []rune{'\'', '`', '[', 0, 0xff, 'й'} {
+		if kbd.IsMapped(char) {
+			t.Errorf("Expected '%c' to be unmapped", char)
+		}
+
+		for _, pair := range [][2]rune{{'a', char}, {char, 'a'}} {
+			_, err := kbd.GetDistance(pair[0], pair[1])
+			if !errors.Is(err, ErrUnknownKey) {
+				t.Errorf("Expected error '%v', got '%v'", ErrUnknownKey, err)
+			}
+
+			var keyErr *UnknownKeyError
+			if !errors.As(err, &keyErr) || keyErr.Key != char {
+				t.Errorf("Expected UnknownKeyError for '%c', got '%v'", char, err)
+			}
+		}
+	}
+}
+
+func Test_ShiftLayer(t *testing.T) {
+	t.Parallel()
+
+	kbd, err := NewQWERTY()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testData := []struct {
+		A, B     rune
+		Expected int
+	}{
+		{'a', 'A', 4},  // a -> left Shift (-1, 3) -> a
+		{'A', 's', 1},  // The finger stays on the 'a' key
+		{'l', 'L', 6},  // l (8, 2) -> right Shift (10, 3) -> l
+		{'s', '!', 7},  // s -> left Shift -> 1
+		{'p', '+', 7},  // p (9, 1) -> right Shift -> = (11, 0)
+		{'m', 'Q', 10}, // m (6, 3) -> left Shift -> q (0, 1)
+	}
+
+	for _, testCase := range testData {
+		dist, err := kbd.GetDistance(testCase.A, testCase.B)
+		if err != nil {
+			t.Error(err)
+		}
+
+		if dist != testCase.Expected {
+			t.Errorf("Expected distance between '%s' and '%s': %d; got: %d",
+				string(testCase.A), string(testCase.B), testCase.Expected, dist)
+		}
+	}
+}
+
+func Test_AltGrLayer(t *testing.T) {
+	t.Parallel()
+
+	def, err := LoadLayout("testdata/altgr.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kbd, err := NewFromDefinition(def)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testData := []struct {
+		A, B     rune
+		Expected int
+	}{
+		{'a', 'x', 6}, // a -> AltGr (3, 0) -> a
+		{'f', 'y', 3}, // f -> AltGr (3, 0) -> c
+		{'c', 'F', 7}, // c -> Shift (0, 2) -> f
+	}
+
+	for _, testCase := range testData {
+		dist, err := kbd.GetDistance(testCase.A, testCase.B)
+		if err != nil {
+			t.Error(err)
+		}
+
+		if dist != testCase.Expected {
+			t.Errorf("Expected distance between '%s' and '%s': %d; got: %d",
+				string(testCase.A), string(testCase.B), testCase.Expected, dist)
+		}
+	}
+
+	if kbd.IsMapped(' ') || kbd.IsMapped('E') {
+		t.Error("Expected ' ' and 'E' to be unmapped")
+	}
+}
+
+func Test_SpecialKeys(t *testing.T) {
+	t.Parallel()
+
+	kbd, err := NewQWERTY()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testData := []struct {
+		A, B     string
+		Expected int
+	}{
+		{"l", "enter", 3},
+		{"q", "tab", 1},
+		{"v", "space", 3}, // 1.5 + 1 rounded up
+		{"enter", "tab", 13},
+	}
+
+	for _, testCase := range testData {
+		a, err := ParseKey(testCase.A)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		b, err := ParseKey(testCase.B)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		dist, err := kbd.GetDistance(a, b)
+		if err != nil {
+			t.Error(err)
+		}
+
+		if dist != testCase.Expected {
+			t.Errorf("Expected distance between %s and %s: %d; got: %d", testCase.A, testCase.B, testCase.Expected, dist)
+		}
+	}
+
+	if _, err := ParseKey("escape"); !errors.Is(err, ErrUnknownSpecialKey) {
+		t.Errorf("Expected unknown special key error; got: %v", err)
+	}
+
+	if KeyName('\n') != "enter" || KeyName('x') != "x" {
+		t.Error("Expected key names to match ParseKey")
+	}
+
+	def := NewDefinition("", Layout{"ab"}).WithSpecial(map[string]Point{"escape": {X: 0, Y: 0}})
+	if err := def.Validate(); !errors.Is(err, ErrUnknownSpecialKey) {
+		t.Errorf("Expected unknown special key error; got: %v", err)
+	}
+}
@@ -2,6 +2,19 @@ package keyboard
 
 type Layout []string
 
+// ShiftKeys are the left and the right Shift positions for the built-in layouts on the integer grid.
+func ShiftKeys() []Point {
+	return []Point{
+		{X: -1, Y: 3},
+		{X: 10, Y: 3},
+	}
+}
+
+// StaggerANSI is the row offsets of the ANSI keyboard in the key widths, from the number row down.
+func StaggerANSI() []float64 {
+	return []float64{0, 0.5, 0.75, 1.25}
+}
+
 func QWERTY() Layout {
 	return []string{
 		"1234567890-=",
@@ -10,3 +23,223 @@ func QWERTY() Layout {
 		"zxcvbnm",
 	}
 }
+
+func Dvorak() Layout {
+	return []string{
+		"1234567890[]",
+		"',.pyfgcrl",
+		"aoeuidhtns",
+		";qjkxbmwvz",
+	}
+}
+
+func Colemak() Layout {
+	return []string{
+		"1234567890-=",
+		"qwfpgjluy;",
+		"arstdhneio",
+		"zxcvbkm",
+	}
+}
+
+// ColemakDH is the ANSI variant of Colemak Mod-DH.
+func ColemakDH() Layout {
+	return []string{
+		"1234567890-=",
+		"qwfpbjluy;",
+		"arstgmneio",
+		"zxcdvkh",
+	}
+}
+
+func Workman() Layout {
+	return []string{
+		"1234567890-=",
+		"qdrwbjfup;",
+		"ashtgyneoi",
+		"zxmcvkl",
+	}
+}
+
+// AZERTY is the French layout. The number row holds the digits, as they are typed with Shift.
+func AZERTY() Layout {
+	return []string{
+		"1234567890)=",
+		"azertyuiop",
+		"qsdfghjklm",
+		"wxcvbn",
+	}
+}
+
+// QWERTZ is the German layout without the umlaut keys.
+func QWERTZ() Layout {
+	return []string{
+		"1234567890",
+		"qwertzuiop",
+		"asdfghjkl",
+		"yxcvbnm",
+	}
+}
+
+func QWERTYShift() Layout {
+	return []string{
+		"!@#$%^&*()_+",
+		"QWERTYUIOP",
+		"ASDFGHJKL",
+		"ZXCVBNM",
+	}
+}
+
+func DvorakShift() Layout {
+	return []string{
+		"!@#$%^&*(){}",
+		"\"<>PYFGCRL",
+		"AOEUIDHTNS",
+		":QJKXBMWVZ",
+	}
+}
+
+func ColemakShift() Layout {
+	return []string{
+		"!@#$%^&*()_+",
+		"QWFPGJLUY:",
+		"ARSTDHNEIO",
+		"ZXCVBKM",
+	}
+}
+
+func ColemakDHShift() Layout {
+	return []string{
+		"!@#$%^&*()_+",
+		"QWFPBJLUY:",
+		"ARSTGMNEIO",
+		"ZXCDVKH",
+	}
+}
+
+func WorkmanShift() Layout {
+	return []string{
+		"!@#$%^&*()_+",
+		"QDRWBJFUP:",
+		"ASHTGYNEOI",
+		"ZXMCVKL",
+	}
+}
+
+// AZERTYShift has only the capitals and '+', the other shifted characters are not ASCII.
+func AZERTYShift() Layout {
+	return []string{
+		"           +",
+		"AZERTYUIOP",
+		"QSDFGHJKLM",
+		"WXCVBN",
+	}
+}
+
+// QWERTZShift has no '§' on the 3 key, it is not ASCII.
+func QWERTZShift() Layout {
+	return []string{
+		"!\" $%&/()=",
+		"QWERTZUIOP",
+		"ASDFGHJKL",
+		"YXCVBNM",
+	}
+}
+
+// JCUKEN is the Russian ЙЦУКЕН layout. 'ё' is to the left of the number row.
+func JCUKEN() Layout {
+	return []string{
+		"ё1234567890-=",
+		"йцукенгшщзхъ",
+		"фывапролджэ",
+		"ячсмитьбю.",
+	}
+}
+
+func JCUKENShift() Layout {
+	return []string{
+		"Ё!\"№;%:?*()_+",
+		"ЙЦУКЕНГШЩЗХЪ",
+		"ФЫВАПРОЛДЖЭ",
+		"ЯЧСМИТЬБЮ,",
+	}
+}
+
+// Greek is the Greek layout without the dead keys for the accented letters.
+func Greek() Layout {
+	return []string{
+		"1234567890-=",
+		";ςερτυθιοπ",
+		"ασδφγηξκλ",
+		"ζχψωβνμ",
+	}
+}
+
+func GreekShift() Layout {
+	return []string{
+		"!@#$%^&*()_+",
+		":΅ΕΡΤΥΘΙΟΠ",
+		"ΑΣΔΦΓΗΞΚΛ",
+		"ΖΧΨΩΒΝΜ",
+	}
+}
+
+// Hebrew is the standard Israeli layout, the Shift layer has the Latin capitals.
+func Hebrew() Layout {
+	return []string{
+		"1234567890-=",
+		"/'קראטוןםפ",
+		"שדגכעיחלךף",
+		"זסבהנמצתץ.",
+	}
+}
+
+func HebrewShift() Layout {
+	return []string{
+		"!@#$%^&*()_+",
+		"QWERTYUIOP",
+		"ASDFGHJKL:",
+		"ZXCVBNM<>?",
+	}
+}
+
+// DigitsAlphabet is the alphabet of the numeric keypads.
+const DigitsAlphabet = "0123456789"
+
+// Numpad is the calculator-style numeric keypad with 7-8-9 on top.
+func Numpad() Layout {
+	return []string{
+		"789",
+		"456",
+		"123",
+		"0",
+	}
+}
+
+// NumpadDefinition is the numeric keypad with the wide '0' key under '1' and '2'.
+func NumpadDefinition() *Definition {
+	def := NewDefinition("numpad", Numpad())
+	def.Alphabet = DigitsAlphabet
+	def.Rows[3].Offset = 0.5
+
+	return def
+}
+
+// Phone is the phone keypad with 1-2-3 on top.
+func Phone() Layout {
+	return []string{
+		"123",
+		"456",
+		"789",
+		"0",
+	}
+}
+
+// PhoneDefinition is the phone keypad with '0' under '8'.
+func PhoneDefinition() *Definition {
+	def := NewDefinition("phone", Phone())
+	def.Alphabet = DigitsAlphabet
+	def.Rows[3].Offset = 1
+
+	return def
+}
@@ -0,0 +1,232 @@
+package keyboard
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"unicode/utf8"
+
+	pkgerr "github.com/pkg/errors"
+)
+
+var ErrBadMatrix = errors.New("bad distance matrix")
+
+// PairCalculator is any cost model which measures the distance between two characters.
+type PairCalculator interface {
+	GetDistance(a, b rune) (int, error)
+}
+
+// Matrix is the precomputed table of the pairwise distances for the alphabet.
+// It has no geometry, so the stateful cost models (touch typing, mobile) lose
+// their state when precomputed: every pair is measured from the initial position.
+type Matrix struct {
+	alphabet []rune
+	dense    [denseChars]int32 // Alphabet index, -1 if not in the alphabet
+	sparse   map[rune]int32
+	costs    []int // alphabet x alphabet
+}
+
+// NewMatrix measures the distances between all the characters of the alphabet.
+func NewMatrix(calc PairCalculator, alphabet []rune) (*Matrix, error) {
+	m, err := newMatrix(alphabet)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, a := range alphabet {
+		for j, b := range alphabet {
+			dist, err := calc.GetDistance(a, b)
+			if err != nil {
+				return nil, pkgerr.Wrapf(err, "failed measure distance from '%c' to '%c'", a, b)
+			}
+
+			m.costs[i*len(alphabet)+j] = dist
+		}
+	}
+
+	return m, nil
+}
+
+// NewKeyboardMatrix precomputes the distances between all the characters of the keyboard.
+func NewKeyboardMatrix(kbd *Keyboard) (*Matrix, error) {
+	return NewMatrix(kbd, kbd.Chars())
+}
+
+func newMatrix(alphabet []rune) (*Matrix, error) {
+	m := &Matrix{
+		alphabet: alphabet,
+		dense:    [denseChars]int32{},
+		sparse:   make(map[rune]int32),
+		costs:    make([]int, len(alphabet)*len(alphabet)),
+	}
+
+	for i := range m.dense {
+		m.dense[i] = -1
+	}
+
+	for i, char := range alphabet {
+		if m.index(char) >= 0 {
+			return nil, pkgerr.Wrapf(ErrBadMatrix, "duplicate character '%c'", char)
+		}
+
+		if char >= 0 && char < denseChars {
+			m.dense[char] = int32(i)
+		} else {
+			m.sparse[char] = int32(i)
+		}
+	}
+
+	return m, nil
+}
+
+// IsMapped reports whether the character is in the alphabet.
+func (m *Matrix) IsMapped(char rune) bool {
+	return m.index(char) >= 0
+}
+
+// GetDistance returns the precomputed distance.
+func (m *Matrix) GetDistance(a, b rune) (int, error) {
+	i := m.index(a)
+	if i < 0 {
+		return 0, &UnknownKeyError{Key: a}
+	}
+
+	j := m.index(b)
+	if j < 0 {
+		return 0, &UnknownKeyError{Key: b}
+	}
+
+	return m.costs[int(i)*len(m.alphabet)+int(j)], nil
+}
+
+// Alphabet returns the characters of the matrix in the table order.
+func (m *Matrix) Alphabet() []rune {
+	return append([]rune(nil), m.alphabet...)
+}
+
+func (m *Matrix) index(char rune) int32 {
+	if char >= 0 && char < denseChars {
+		return m.dense[char]
+	}
+
+	if idx, ok := m.sparse[char]; ok {
+		return idx
+	}
+
+	return -1
+}
+
+// WriteCSV exports the matrix: the header has the target characters after the empty cell,
+// every row starts with its source character.
+func (m *Matrix) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	record := make([]string, len(m.alphabet)+1)
+
+	for i, char := range m.alphabet {
+		record[i+1] = string(char)
+	}
+
+	if err := writer.Write(record); err != nil {
+		return pkgerr.Wrap(err, "failed write matrix header")
+	}
+
+	for i, char := range m.alphabet {
+		record[0] = string(char)
+
+		for j := 0; j < len(m.alphabet); j++ {
+			record[j+1] = strconv.Itoa(m.costs[i*len(m.alphabet)+j])
+		}
+
+		if err := writer.Write(record); err != nil {
+			return pkgerr.Wrapf(err, "failed write matrix row '%c'", char)
+		}
+	}
+
+	writer.Flush()
+
+	return pkgerr.Wrap(writer.Error(), "failed write matrix")
+}
+
+// SaveMatrix exports the matrix to the CSV file.
+func SaveMatrix(path string, m *Matrix) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return pkgerr.Wrapf(err, "failed create matrix file '%s'", path)
+	}
+
+	if err := m.WriteCSV(f); err != nil {
+		f.Close()
+		return pkgerr.Wrapf(err, "failed save matrix file '%s'", path)
+	}
+
+	return pkgerr.Wrapf(f.Close(), "failed close matrix file '%s'", path)
+}
+
+// ReadMatrixCSV imports the matrix written by WriteCSV.
+func ReadMatrixCSV(r io.Reader) (*Matrix, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, pkgerr.Wrap(err, "failed read matrix")
+	}
+
+	if len(records) == 0 {
+		return nil, pkgerr.Wrap(ErrBadMatrix, "no header")
+	}
+
+	alphabet := make([]rune, 0, len(records[0]))
+
+	for _, cell := range records[0][1:] {
+		char, size := utf8.DecodeRuneInString(cell)
+		if size == 0 || size != len(cell) {
+			return nil, pkgerr.Wrapf(ErrBadMatrix, "bad header character '%s'", cell)
+		}
+
+		alphabet = append(alphabet, char)
+	}
+
+	if len(records) != len(alphabet)+1 {
+		return nil, pkgerr.Wrapf(ErrBadMatrix, "%d rows for %d characters", len(records)-1, len(alphabet))
+	}
+
+	m, err := newMatrix(alphabet)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, record := range records[1:] {
+		if len(record) != len(alphabet)+1 || record[0] != string(alphabet[i]) {
+			return nil, pkgerr.Wrapf(ErrBadMatrix, "row %d: expected '%c' and %d costs", i+1, alphabet[i], len(alphabet))
+		}
+
+		for j, cell := range record[1:] {
+			cost, err := strconv.Atoi(cell)
+			if err != nil {
+				return nil, pkgerr.Wrapf(ErrBadMatrix, "row '%c', column '%c': %v", alphabet[i], alphabet[j], err)
+			}
+
+			m.costs[i*len(alphabet)+j] = cost
+		}
+	}
+
+	return m, nil
+}
+
+// LoadMatrix imports the matrix from the CSV file.
+func LoadMatrix(path string) (*Matrix, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, pkgerr.Wrapf(err, "failed open matrix file '%s'", path)
+	}
+
+	defer f.Close()
+
+	m, err := ReadMatrixCSV(f)
+	if err != nil {
+		return nil, pkgerr.Wrapf(err, "failed load matrix file '%s'", path)
+	}
+
+	return m, nil
+}
@@ -0,0 +1,106 @@
+package keyboard
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func Test_MatrixMatchesKeyboard(t *testing.T) {
+	t.Parallel()
+
+	kbd, err := NewQWERTY(WithMetric(Euclidean), WithResolution(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	matrix, err := NewKeyboardMatrix(kbd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, a := range kbd.Chars() {
+		for _, b := range kbd.Chars() {
+			expected, err := kbd.GetDistance(a, b)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			dist, err := matrix.GetDistance(a, b)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if dist != expected {
+				t.Errorf("Expected distance between '%c' and '%c': %d; got: %d", a, b, expected, dist)
+			}
+		}
+	}
+
+	if _, err := matrix.GetDistance('a', 'ж'); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Expected unknown key error; got: %v", err)
+	}
+
+	if matrix.IsMapped('ж') || !matrix.IsMapped('Q') {
+		t.Error("Expected the matrix alphabet to match the keyboard")
+	}
+}
+
+func Test_MatrixCSV(t *testing.T) {
+	t.Parallel()
+
+	kbd, err := New(Layout{"a,", "\"ж"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	matrix, err := NewKeyboardMatrix(kbd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := matrix.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := ReadMatrixCSV(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(loaded.Alphabet()) != string(matrix.Alphabet()) {
+		t.Errorf("Expected alphabet %q; got: %q", string(matrix.Alphabet()), string(loaded.Alphabet()))
+	}
+
+	for _, a := range matrix.Alphabet() {
+		for _, b := range matrix.Alphabet() {
+			expected, _ := matrix.GetDistance(a, b)
+
+			dist, err := loaded.GetDistance(a, b)
+			if err != nil || dist != expected {
+				t.Errorf("Expected distance between '%c' and '%c': %d; got: %d, %v", a, b, expected, dist, err)
+			}
+		}
+	}
+}
+
+func Test_ReadMatrixCSVErrors(t *testing.T) {
+	t.Parallel()
+
+	testData := []string{
+		"",
+		",ab\na,0\nb,0\n",
+		",a,b\na,0,1\n",
+		",a,a\na,0,1\na,1,0\n",
+		",a,b\nb,0,1\na,1,0\n",
+		",a,b\na,0,x\nb,1,0\n",
+	}
+
+	for _, data := range testData {
+		if _, err := ReadMatrixCSV(strings.NewReader(data)); !errors.Is(err, ErrBadMatrix) {
+			t.Errorf("Expected bad matrix error for %q; got: %v", data, err)
+		}
+	}
+}
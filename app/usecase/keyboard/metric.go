@@ -0,0 +1,83 @@
+package keyboard
+
+import (
+	"errors"
+	"math"
+	"strings"
+
+	pkgerr "github.com/pkg/errors"
+)
+
+// Metric is the way the distance between two keys is measured.
+type Metric int
+
+const (
+	Manhattan Metric = iota // Sum of the horizontal and vertical moves
+	Euclidean               // Straight line between the key centers
+	Chebyshev               // Diagonal moves cost the same as the straight ones
+	KeyHops                 // Number of hops between the adjacent keys, staggered rows make it hex-like
+)
+
+// adjacencyEps tolerates float errors when comparing the key positions.
+const adjacencyEps = 1e-6
+
+var ErrUnknownMetric = errors.New("unknown metric")
+
+var metricNames = map[Metric]string{
+	Manhattan: "manhattan",
+	Euclidean: "euclidean",
+	Chebyshev: "chebyshev",
+	KeyHops:   "hops",
+}
+
+// ParseMetric returns the metric by its name: manhattan, euclidean, chebyshev or hops.
+func ParseMetric(name string) (Metric, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	for metric, metricName := range metricNames {
+		if metricName == name {
+			return metric, nil
+		}
+	}
+
+	return Manhattan, pkgerr.Wrapf(ErrUnknownMetric, "'%s'", name)
+}
+
+func (m Metric) String() string {
+	if name, ok := metricNames[m]; ok {
+		return name
+	}
+
+	return "unknown"
+}
+
+// measure returns the geometric distance between the points.
+// KeyHops is not geometric and is calculated by the keyboard itself.
+func (m Metric) measure(a, b Point) float64 {
+	dx := math.Abs(a.X - b.X)
+	dy := math.Abs(a.Y - b.Y)
+
+	switch m {
+	case Euclidean:
+		return math.Hypot(dx, dy)
+	case Chebyshev:
+		return math.Max(dx, dy)
+	case Manhattan, KeyHops:
+		return dx + dy
+	}
+
+	return dx + dy
+}
+
+// adjacent reports whether the keys touch each other: the neighbours in the same row
+// or the keys in the neighbour rows which overlap horizontally.
+func adjacent(a, b Point) bool {
+	dx := math.Abs(a.X - b.X)
+	dy := math.Abs(a.Y - b.Y)
+
+	if dy < adjacencyEps {
+		return dx <= 1+adjacencyEps
+	}
+
+	return dy <= 1+adjacencyEps && dx < 1-adjacencyEps
+}
@@ -0,0 +1,395 @@
+package keyboard
+
+import (
+	"errors"
+	"math"
+
+	pkgerr "github.com/pkg/errors"
+)
+
+var (
+	ErrNoPages      = errors.New("mobile keyboard has no pages")
+	ErrUnknownPage  = errors.New("unknown mobile keyboard page")
+	ErrNoPageSwitch = errors.New("no way to switch the mobile keyboard page")
+)
+
+// Thumbs of the two-thumb typing.
+const (
+	LeftThumbIdx = iota
+	RightThumbIdx
+
+	thumbsCount
+)
+
+// MobilePage is a single page of the touchscreen keyboard, e.g. letters or digits.
+// Rows use the same geometry as the physical keyboard, the Shift layer is typed by
+// tapping the page Shift key before the character.
+type MobilePage struct {
+	Name     string           `json:"name"`
+	Rows     []Row            `json:"rows"`
+	Shift    *Point           `json:"shift,omitempty"`
+	Switches map[string]Point `json:"switches"` // Keys switching to the other pages by their names
+}
+
+// MobileDefinition is the touchscreen keyboard: the first page is shown initially,
+// the thumbs start at their positions and the keys to the left of the center are
+// tapped by the left thumb.
+type MobileDefinition struct {
+	Name           string             `json:"name"`
+	Pages          []MobilePage       `json:"pages"`
+	Thumbs         [thumbsCount]Point `json:"thumbs"`
+	Center         float64            `json:"center"`
+	PageSwitchCost float64            `json:"page_switch_cost"` // Extra cost of a page switch in the key widths
+}
+
+// mobileKey is the position of the character on the page.
+type mobileKey struct {
+	page     int
+	position Point
+	shift    bool
+}
+
+// Mobile is the two-thumb touchscreen keyboard cost model. Every tap costs the travel of its thumb
+// from the previous tap of the same thumb, the page switches cost the tap on the switch key and
+// the page switch cost.
+type Mobile struct {
+	name     string
+	keys     map[rune][]mobileKey
+	shifts   []*Point
+	switches [][]*Point // From page to page
+	routes   [][]int    // Next page on the way from page to page, -1 if unreachable
+	thumbs   [thumbsCount]Point
+	center   float64
+	switchTo float64
+
+	resolution float64
+}
+
+// mobileState is the shown page and the thumbs positions.
+type mobileState struct {
+	page   int
+	thumbs [thumbsCount]Point
+}
+
+// PhoneQWERTY is the phone keyboard with the letters, digits and symbols pages.
+func PhoneQWERTY() *MobileDefinition {
+	const (
+		bottomRow = 3
+		spaceX    = 4.5
+	)
+
+	space := Row{
+		Name:        "space",
+		Keys:        " ",
+		Shift:       "",
+		AltGr:       "",
+		Fingers:     "",
+		Offset:      0,
+		Coordinates: []Point{{X: spaceX, Y: bottomRow}},
+		Widths:      nil,
+	}
+
+	row := func(name, keys, shift string, offset float64) Row {
+		return Row{Name: name, Keys: keys, Shift: shift, AltGr: "", Fingers: "", Offset: offset, Coordinates: nil, Widths: nil}
+	}
+
+	return &MobileDefinition{
+		Name: "phone-qwerty",
+		Pages: []MobilePage{
+			{
+				Name: "letters",
+				Rows: []Row{
+					row("top", "qwertyuiop", "QWERTYUIOP", 0),
+					row("home", "asdfghjkl", "ASDFGHJKL", 0.5), //nolint:gomnd // half key offset
+					row("bottom", "zxcvbnm", "ZXCVBNM", 1.5),   //nolint:gomnd // after Shift
+					space,
+				},
+				Shift:    &Point{X: 0, Y: 2},
+				Switches: map[string]Point{"numbers": {X: 0, Y: bottomRow}},
+			},
+			{
+				Name: "numbers",
+				Rows: []Row{
+					row("digits", "1234567890", "", 0),
+					row("punctuation", "-/:;()$&@\"", "", 0),
+					row("bottom", ".,?!'", "", 2), //nolint:gomnd // after the symbols switch
+					space,
+				},
+				Shift:    nil,
+				Switches: map[string]Point{"letters": {X: 0, Y: bottomRow}, "symbols": {X: 0, Y: 2}},
+			},
+			{
+				Name: "symbols",
+				Rows: []Row{
+					row("brackets", "[]{}#%^*+=", "", 0),
+					row("other", "_\\|~<>€£¥•", "", 0),
+					row("bottom", ".,?!'", "", 2), //nolint:gomnd // after the numbers switch
+					space,
+				},
+				Shift:    nil,
+				Switches: map[string]Point{"letters": {X: 0, Y: bottomRow}, "numbers": {X: 0, Y: 2}},
+			},
+		},
+		Thumbs:         [thumbsCount]Point{{X: 1, Y: bottomRow}, {X: 8, Y: bottomRow}},
+		Center:         spaceX,
+		PageSwitchCost: 1,
+	}
+}
+
+// NewMobile makes the touchscreen keyboard cost model,
+// the resolution is the number of distance units per key width.
+func NewMobile(def *MobileDefinition, resolution int) (*Mobile, error) {
+	if len(def.Pages) == 0 {
+		return nil, ErrNoPages
+	}
+
+	if resolution <= 0 {
+		return nil, pkgerr.Wrapf(ErrBadResolution, "%v", resolution)
+	}
+
+	mobile := &Mobile{
+		name:     def.Name,
+		keys:     make(map[rune][]mobileKey),
+		shifts:   make([]*Point, len(def.Pages)),
+		switches: make([][]*Point, len(def.Pages)),
+		routes:   nil,
+		thumbs:   def.Thumbs,
+		center:   def.Center,
+		switchTo: def.PageSwitchCost,
+
+		resolution: float64(resolution),
+	}
+
+	pages := make(map[string]int, len(def.Pages))
+	for i := 0; i < len(def.Pages); i++ {
+		pages[def.Pages[i].Name] = i
+	}
+
+	for i := 0; i < len(def.Pages); i++ {
+		if err := mobile.addPage(i, &def.Pages[i], pages); err != nil {
+			return nil, pkgerr.Wrapf(err, "page '%s'", def.Pages[i].Name)
+		}
+	}
+
+	mobile.routes = calcRoutes(mobile.switches)
+
+	return mobile, nil
+}
+
+func (m *Mobile) addPage(idx int, page *MobilePage, pages map[string]int) error {
+	pageDef := &Definition{
+		Version:   DefinitionVersion,
+		Name:      page.Name,
+		Metadata:  nil,
+		Alphabet:  "",
+		Rows:      page.Rows,
+		Modifiers: nil,
+		Special:   nil,
+		Home:      nil,
+		Effort:    nil,
+	}
+
+	if page.Shift != nil {
+		pageDef.Modifiers = &Modifiers{Shift: []Point{*page.Shift}, AltGr: nil}
+	}
+
+	if err := pageDef.Validate(); err != nil {
+		return err
+	}
+
+	m.shifts[idx] = page.Shift
+	m.switches[idx] = make([]*Point, len(pages))
+
+	for name, position := range page.Switches {
+		target, ok := pages[name]
+		if !ok {
+			return pkgerr.Wrapf(ErrUnknownPage, "'%s'", name)
+		}
+
+		position := position
+		m.switches[idx][target] = &position
+	}
+
+	for i := 0; i < len(page.Rows); i++ {
+		row := &page.Rows[i]
+		shift := []rune(row.Shift)
+		j := 0
+
+		for _, char := range row.Keys {
+			position := row.position(i, j)
+
+			m.keys[char] = append(m.keys[char], mobileKey{page: idx, position: position, shift: false})
+
+			if j < len(shift) && shift[j] != noChar {
+				m.keys[shift[j]] = append(m.keys[shift[j]], mobileKey{page: idx, position: position, shift: true})
+			}
+
+			j++
+		}
+	}
+
+	return nil
+}
+
+// IsMapped reports whether the character is on any page.
+func (m *Mobile) IsMapped(char rune) bool {
+	_, ok := m.keys[char]
+	return ok
+}
+
+// Name returns the layout name.
+func (m *Mobile) Name() string {
+	return m.name
+}
+
+// GetDistance returns the cost of typing b right after a, starting from the first page.
+func (m *Mobile) GetDistance(a, b rune) (int, error) {
+	state := m.newState()
+
+	first, err := m.press(&state, a)
+	if err != nil {
+		return 0, err
+	}
+
+	second, err := m.press(&state, b)
+	if err != nil {
+		return 0, err
+	}
+
+	return m.round(first+second) - m.round(first), nil
+}
+
+// GetSequenceDistance returns the cost of typing the whole sequence from the initial state,
+// the first tap included.
+func (m *Mobile) GetSequenceDistance(seq string) (int, error) {
+	state := m.newState()
+	total := 0.0
+
+	for _, char := range seq {
+		cost, err := m.press(&state, char)
+		if err != nil {
+			return 0, pkgerr.Wrapf(err, "sequence '%s'", seq)
+		}
+
+		total += cost
+	}
+
+	return m.round(total), nil
+}
+
+func (m *Mobile) newState() mobileState {
+	return mobileState{
+		page:   0,
+		thumbs: m.thumbs,
+	}
+}
+
+// press types the character on the cheapest page, switching the pages if needed.
+func (m *Mobile) press(state *mobileState, char rune) (float64, error) {
+	keys, ok := m.keys[char]
+	if !ok {
+		return 0, &UnknownKeyError{Key: char}
+	}
+
+	var (
+		best      = -1.0
+		bestState mobileState
+	)
+
+	for _, key := range keys {
+		next := *state
+
+		cost, ok := m.switchPage(&next, key.page)
+		if !ok {
+			continue
+		}
+
+		if key.shift {
+			cost += m.tap(&next, *m.shifts[key.page])
+		}
+
+		cost += m.tap(&next, key.position)
+
+		if best < 0 || cost < best {
+			best = cost
+			bestState = next
+		}
+	}
+
+	if best < 0 {
+		return 0, pkgerr.Wrapf(ErrNoPageSwitch, "to type '%c'", char)
+	}
+
+	*state = bestState
+
+	return best, nil
+}
+
+// switchPage taps the switch keys to show the page with the fewest switches.
+func (m *Mobile) switchPage(state *mobileState, page int) (float64, bool) {
+	cost := 0.0
+
+	for state.page != page {
+		next := m.routes[state.page][page]
+		if next < 0 {
+			return 0, false
+		}
+
+		cost += m.tap(state, *m.switches[state.page][next]) + m.switchTo
+		state.page = next
+	}
+
+	return cost, true
+}
+
+// calcRoutes finds the next page on the shortest switch path between all the pages with BFS.
+func calcRoutes(switches [][]*Point) [][]int {
+	routes := make([][]int, len(switches))
+
+	for to := 0; to < len(switches); to++ {
+		// Search backwards from the target page, so the first step is known for every page
+		for from := range routes {
+			if routes[from] == nil {
+				routes[from] = make([]int, len(switches))
+			}
+
+			routes[from][to] = -1
+		}
+
+		routes[to][to] = to
+		queue := []int{to}
+
+		for len(queue) != 0 {
+			current := queue[0]
+			queue = queue[1:]
+
+			for from := 0; from < len(switches); from++ {
+				if routes[from][to] >= 0 || switches[from][current] == nil {
+					continue
+				}
+
+				routes[from][to] = current
+				queue = append(queue, from)
+			}
+		}
+	}
+
+	return routes
+}
+
+// tap moves the thumb of the screen half to the position.
+func (m *Mobile) tap(state *mobileState, position Point) float64 {
+	thumb := LeftThumbIdx
+	if position.X >= m.center {
+		thumb = RightThumbIdx
+	}
+
+	cost := Euclidean.measure(state.thumbs[thumb], position)
+	state.thumbs[thumb] = position
+
+	return cost
+}
+
+func (m *Mobile) round(cost float64) int {
+	return int(math.Round(cost * m.resolution))
+}
@@ -0,0 +1,62 @@
+package keyboard
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_Mobile(t *testing.T) {
+	t.Parallel()
+
+	mobile, err := NewMobile(PhoneQWERTY(), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testData := []struct {
+		Seq      string
+		Expected int
+	}{
+		{"q", 316},    // Left thumb (1, 3) -> q (0, 0)
+		{"qp", 632},   // Right thumb (8, 3) -> p (9, 0)
+		{"1", 500},    // Switch (0, 3) + switch cost, then 1 (0, 0)
+		{"a1a", 1418}, // Back to the letters page with the same switch key
+		{"A", 253},    // Shift (0, 2), then a (0.5, 1)
+		{"€", 683},    // Letters -> numbers -> symbols, then € (6, 1) by the right thumb
+		{"a a", 556},  // Space (4.5, 3) is tapped by the right thumb, the left one stays on a
+	}
+
+	for _, testCase := range testData {
+		dist, err := mobile.GetSequenceDistance(testCase.Seq)
+		if err != nil {
+			t.Error(err)
+		}
+
+		if dist != testCase.Expected {
+			t.Errorf("Expected cost of '%s': %d; got: %d", testCase.Seq, testCase.Expected, dist)
+		}
+	}
+
+	if !mobile.IsMapped('?') || mobile.IsMapped('й') {
+		t.Error("Expected '?' to be mapped and 'й' to be unmapped")
+	}
+
+	if _, err := mobile.GetDistance('a', 'й'); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Expected error '%v', got '%v'", ErrUnknownKey, err)
+	}
+}
+
+func Test_MobileBadDefinition(t *testing.T) {
+	t.Parallel()
+
+	def := PhoneQWERTY()
+	def.Pages[0].Switches["emoji"] = Point{X: 1, Y: 3}
+
+	if _, err := NewMobile(def, 1); !errors.Is(err, ErrUnknownPage) {
+		t.Errorf("Expected error '%v', got '%v'", ErrUnknownPage, err)
+	}
+
+	if _, err := NewMobile(&MobileDefinition{}, 1); !errors.Is(err, ErrNoPages) { //nolint:exhaustruct // empty
+		t.Errorf("Expected error '%v', got '%v'", ErrNoPages, err)
+	}
+}
@@ -0,0 +1,155 @@
+package keyboard
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+
+	pkgerr "github.com/pkg/errors"
+)
+
+var (
+	ErrUnknownLayout = errors.New("unknown layout")
+	ErrLayoutExists  = errors.New("layout is already registered")
+	ErrNoLayoutName  = errors.New("layout has no name")
+)
+
+// Registry keeps layout definitions by their case-insensitive names.
+type Registry struct {
+	lock    sync.RWMutex
+	layouts map[string]*Definition
+}
+
+var defaultRegistry = NewRegistry()
+
+// NewRegistry makes a registry with the built-in layouts.
+func NewRegistry() *Registry {
+	r := &Registry{
+		lock:    sync.RWMutex{},
+		layouts: make(map[string]*Definition),
+	}
+
+	builtin := map[string]struct {
+		layout, shift Layout
+		alphabet      string
+	}{
+		"qwerty":     {QWERTY(), QWERTYShift(), DefaultAlphabet},
+		"dvorak":     {Dvorak(), DvorakShift(), DefaultAlphabet},
+		"colemak":    {Colemak(), ColemakShift(), DefaultAlphabet},
+		"colemak-dh": {ColemakDH(), ColemakDHShift(), DefaultAlphabet},
+		"workman":    {Workman(), WorkmanShift(), DefaultAlphabet},
+		"azerty":     {AZERTY(), AZERTYShift(), DefaultAlphabet},
+		"qwertz":     {QWERTZ(), QWERTZShift(), DefaultAlphabet},
+		"jcuken":     {JCUKEN(), JCUKENShift(), "абвгдеёжзийклмнопрстуфхцчшщъыьэюя"},
+		"greek":      {Greek(), GreekShift(), "αβγδεζηθικλμνξοπρστυφχψως"},
+		"hebrew":     {Hebrew(), HebrewShift(), "אבגדהוזחטיכךלמםנןסעפףצץקרשת"},
+	}
+
+	for name, layers := range builtin {
+		def := NewDefinition(name, layers.layout).WithShift(layers.shift, ShiftKeys()...)
+		def.Alphabet = layers.alphabet
+
+		if name == "jcuken" {
+			def.Rows[0].Offset = -1 // Keep the digits in the same columns as on the other layouts
+		}
+
+		def = def.WithSpecial(SpecialKeys()).WithStandardFingers()
+
+		if err := r.Register(def); err != nil {
+			panic(err)
+		}
+	}
+
+	for _, def := range []*Definition{NumpadDefinition(), PhoneDefinition()} {
+		if err := r.Register(def); err != nil {
+			panic(err)
+		}
+	}
+
+	return r
+}
+
+// Register validates and adds the definition to the registry.
+func (r *Registry) Register(def *Definition) error {
+	name := normalizeName(def.Name)
+	if name == "" {
+		return ErrNoLayoutName
+	}
+
+	if err := def.Validate(); err != nil {
+		return pkgerr.Wrapf(err, "bad layout '%s'", def.Name)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if _, ok := r.layouts[name]; ok {
+		return pkgerr.Wrapf(ErrLayoutExists, "'%s'", def.Name)
+	}
+
+	r.layouts[name] = def.Clone()
+
+	return nil
+}
+
+// Lookup returns a copy of the registered definition.
+func (r *Registry) Lookup(name string) (*Definition, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	def, ok := r.layouts[normalizeName(name)]
+	if !ok {
+		return nil, pkgerr.Wrapf(ErrUnknownLayout, "'%s', available: %s", name, strings.Join(r.names(), ", "))
+	}
+
+	return def.Clone(), nil
+}
+
+// Names returns the sorted names of all registered layouts.
+func (r *Registry) Names() []string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return r.names()
+}
+
+func (r *Registry) names() []string {
+	names := make([]string, 0, len(r.layouts))
+	for name := range r.layouts {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Register adds the definition to the default registry.
+func Register(def *Definition) error {
+	return defaultRegistry.Register(def)
+}
+
+// Lookup looks for the definition in the default registry.
+func Lookup(name string) (*Definition, error) {
+	return defaultRegistry.Lookup(name)
+}
+
+// Names lists the layouts of the default registry.
+func Names() []string {
+	return defaultRegistry.Names()
+}
+
+// NewByName makes the keyboard with the layout from the default registry.
+func NewByName(name string, opts ...Option) (*Keyboard, error) {
+	def, err := Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewFromDefinition(def, opts...)
+}
+
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
@@ -0,0 +1,125 @@
+package keyboard
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_RegistryBuiltin(t *testing.T) {
+	t.Parallel()
+
+	expected := []string{
+		"azerty", "colemak", "colemak-dh", "dvorak", "greek", "hebrew", "jcuken", "numpad", "phone", "qwerty",
+		"qwertz", "workman",
+	}
+	names := NewRegistry().Names()
+
+	if len(names) != len(expected) {
+		t.Fatalf("Expected layouts %v, got %v", expected, names)
+	}
+
+	for i := 0; i < len(expected); i++ {
+		if names[i] != expected[i] {
+			t.Fatalf("Expected layouts %v, got %v", expected, names)
+		}
+
+		if _, err := NewByName(names[i]); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func Test_RegistryLookup(t *testing.T) {
+	t.Parallel()
+
+	kbd, err := NewByName("Dvorak")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 'a' and 's' are on the opposite sides of the Dvorak home row
+	dist, err := kbd.GetDistance('a', 's')
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := 9; dist != expected {
+		t.Errorf("Expected: %v, got: %v", expected, dist)
+	}
+
+	if _, err := Lookup("unknown"); !errors.Is(err, ErrUnknownLayout) {
+		t.Errorf("Expected error '%v', got '%v'", ErrUnknownLayout, err)
+	}
+}
+
+func Test_RegistryRegister(t *testing.T) {
+	t.Parallel()
+
+	r := NewRegistry()
+
+	def := NewDefinition("custom", Layout{"abc", "def"})
+	if err := r.Register(def); err != nil {
+		t.Fatal(err)
+	}
+
+	// The registry keeps its own copy
+	def.Rows[0].Keys = "xyz"
+
+	got, err := r.Lookup("CUSTOM")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Rows[0].Keys != "abc" {
+		t.Errorf("Registered layout was modified: %v", got.Layout())
+	}
+
+	if err := r.Register(def); !errors.Is(err, ErrLayoutExists) {
+		t.Errorf("Expected error '%v', got '%v'", ErrLayoutExists, err)
+	}
+
+	if err := r.Register(NewDefinition("", Layout{"abc"})); !errors.Is(err, ErrNoLayoutName) {
+		t.Errorf("Expected error '%v', got '%v'", ErrNoLayoutName, err)
+	}
+
+	if err := r.Register(NewDefinition("bad", Layout{"aa"})); !errors.Is(err, ErrDuplicateKey) {
+		t.Errorf("Expected error '%v', got '%v'", ErrDuplicateKey, err)
+	}
+}
+
+func Test_RegistryNonLatin(t *testing.T) {
+	t.Parallel()
+
+	testData := []struct {
+		Layout   string
+		A, B     rune
+		Expected int
+	}{
+		{"jcuken", 'ф', 'в', 2},
+		{"jcuken", 'ё', '1', 1},
+		{"jcuken", 'й', 'Ё', 6}, // й -> left Shift (-1, 3) -> ё (-1, 0)
+		{"greek", 'α', 'λ', 8},
+		{"hebrew", 'ש', 'ף', 9},
+		{"numpad", '7', '3', 4},
+		{"numpad", '0', '2', 2}, // Wide '0' under '1' and '2'
+		{"phone", '1', '9', 4},
+		{"phone", '0', '8', 1},
+	}
+
+	for _, testCase := range testData {
+		kbd, err := NewByName(testCase.Layout)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		dist, err := kbd.GetDistance(testCase.A, testCase.B)
+		if err != nil {
+			t.Error(err)
+		}
+
+		if dist != testCase.Expected {
+			t.Errorf("%s: expected distance between '%c' and '%c': %d; got: %d",
+				testCase.Layout, testCase.A, testCase.B, testCase.Expected, dist)
+		}
+	}
+}
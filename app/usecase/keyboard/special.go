@@ -0,0 +1,86 @@
+package keyboard
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"unicode/utf8"
+
+	pkgerr "github.com/pkg/errors"
+)
+
+var ErrUnknownSpecialKey = errors.New("unknown special key")
+
+// specialKeys are the non-character keys with the characters they type.
+var specialKeys = map[string]rune{
+	"enter": '\n',
+	"tab":   '\t',
+	"space": ' ',
+}
+
+// SpecialKeys are the Tab, Enter and Space positions for the built-in layouts on the integer grid.
+func SpecialKeys() map[string]Point {
+	return map[string]Point{
+		"tab":   {X: -1, Y: 1},
+		"enter": {X: 11, Y: 2},
+		"space": {X: 4.5, Y: 4},
+	}
+}
+
+// ParseKey returns the character typed by the key: the special key name, e.g. "enter",
+// or the character itself.
+func ParseKey(name string) (rune, error) {
+	if char, ok := specialKeys[name]; ok {
+		return char, nil
+	}
+
+	if char, size := utf8.DecodeRuneInString(name); size != 0 && size == len(name) {
+		return char, nil
+	}
+
+	return 0, pkgerr.Wrapf(ErrUnknownSpecialKey, "'%s'", name)
+}
+
+// KeyName is the printable name of the key, the reverse of ParseKey.
+func KeyName(char rune) string {
+	for name, special := range specialKeys {
+		if special == char {
+			return name
+		}
+	}
+
+	return string(char)
+}
+
+// WithSpecial returns a copy of the definition with the special keys at the positions.
+func (d *Definition) WithSpecial(positions map[string]Point) *Definition {
+	clone := d.Clone()
+
+	clone.Special = make(map[string]Point, len(positions))
+	for name, position := range positions {
+		clone.Special[name] = position
+	}
+
+	return clone
+}
+
+// specialNames returns the special key names in the stable order.
+func (d *Definition) specialNames() []string {
+	names := make([]string, 0, len(d.Special))
+	for name := range d.Special {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// specialFinger is the touch typing finger of the special key, the thumbs press Space.
+func specialFinger(name string, position Point) Finger {
+	if name == "space" {
+		return RightThumb
+	}
+
+	return columnFinger(int(math.Round(position.X)))
+}
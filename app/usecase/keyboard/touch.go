@@ -0,0 +1,183 @@
+package keyboard
+
+import (
+	"errors"
+	"math"
+
+	pkgerr "github.com/pkg/errors"
+)
+
+var ErrNoFingers = errors.New("layout has no finger home positions")
+
+// TouchTyping is the multi-finger cost model. Every key is pressed by its own finger,
+// which travels from the key it pressed last, all the fingers start on their home positions.
+// Pressing two different keys in a row with the same finger costs the extra penalty.
+// The keypress effort of the layout is added for every keystroke.
+type TouchTyping struct {
+	kbd               *Keyboard
+	sameFingerPenalty float64
+}
+
+// typingState is the position of every finger while typing the sequence.
+type typingState struct {
+	fingers    [FingersCount]int
+	lastFinger Finger
+	lastSlot   int
+}
+
+// NewTouchTyping makes the touch typing cost model for the keyboard with the finger home positions.
+// The same finger penalty is in the key widths.
+func NewTouchTyping(kbd *Keyboard, sameFingerPenalty float64) (*TouchTyping, error) {
+	if !kbd.hasHome {
+		return nil, ErrNoFingers
+	}
+
+	return &TouchTyping{
+		kbd:               kbd,
+		sameFingerPenalty: sameFingerPenalty,
+	}, nil
+}
+
+// IsMapped reports whether the character is on the layout.
+func (t *TouchTyping) IsMapped(char rune) bool {
+	return t.kbd.IsMapped(char)
+}
+
+// Name returns the layout name.
+func (t *TouchTyping) Name() string {
+	return t.kbd.Name()
+}
+
+// Locate returns the position of the character key on the keyboard.
+func (t *TouchTyping) Locate(char rune) (x, y float64, ok bool) {
+	return t.kbd.Locate(char)
+}
+
+// GetDistance returns the cost of typing b right after a, when all the other fingers are at home.
+func (t *TouchTyping) GetDistance(a, b rune) (int, error) {
+	state := t.newState()
+
+	first, err := t.press(&state, a)
+	if err != nil {
+		return 0, err
+	}
+
+	second, err := t.press(&state, b)
+	if err != nil {
+		return 0, err
+	}
+
+	return t.round(first+second) - t.round(first), nil
+}
+
+// GetSequenceDistance returns the cost of typing the whole sequence from the home positions,
+// the first keystroke included.
+func (t *TouchTyping) GetSequenceDistance(seq string) (int, error) {
+	state := t.newState()
+	total := 0.0
+
+	for _, char := range seq {
+		cost, err := t.press(&state, char)
+		if err != nil {
+			return 0, pkgerr.Wrapf(err, "sequence '%s'", seq)
+		}
+
+		total += cost
+	}
+
+	return t.round(total), nil
+}
+
+func (t *TouchTyping) newState() typingState {
+	return typingState{
+		fingers:    t.kbd.home,
+		lastFinger: FingersCount,
+		lastSlot:   -1,
+	}
+}
+
+// press moves the fingers to type the character and returns the cost of the move.
+// The characters on the other layers are typed with the modifier held by another finger.
+func (t *TouchTyping) press(state *typingState, char rune) (float64, error) {
+	key, ok := t.kbd.lookup(char)
+	if !ok {
+		return 0, &UnknownKeyError{Key: char}
+	}
+
+	if key.layer == Base {
+		return t.pressSlot(state, key.slot)
+	}
+
+	var (
+		best      = -1.0
+		bestState typingState
+	)
+
+	for _, modifier := range t.kbd.modifiers[key.layer] {
+		if t.kbd.fingers[modifier] == t.kbd.fingers[key.slot] {
+			continue
+		}
+
+		next := *state
+
+		modifierCost, err := t.pressSlot(&next, modifier)
+		if err != nil {
+			continue
+		}
+
+		keyCost, err := t.pressSlot(&next, key.slot)
+		if err != nil {
+			continue
+		}
+
+		if cost := modifierCost + keyCost; best < 0 || cost < best {
+			best = cost
+			bestState = next
+		}
+	}
+
+	if best < 0 {
+		return 0, pkgerr.Wrapf(ErrUnreachable, "'%c' through %s", char, key.layer)
+	}
+
+	*state = bestState
+
+	return best, nil
+}
+
+func (t *TouchTyping) pressSlot(state *typingState, slot int) (float64, error) {
+	finger := t.kbd.fingers[slot]
+
+	cost := t.kbd.dist[state.fingers[finger]*len(t.kbd.slots)+slot]
+	if cost < 0 {
+		return 0, ErrUnreachable
+	}
+
+	if finger == state.lastFinger && slot != state.lastSlot {
+		cost += t.sameFingerPenalty
+	}
+
+	cost += t.kbd.pressCost(state.lastSlot, slot)
+
+	state.fingers[finger] = slot
+	state.lastFinger = finger
+	state.lastSlot = slot
+
+	return cost, nil
+}
+
+// GetKeyCost returns the cost of typing the character first, from the home positions, in the resolution units.
+func (t *TouchTyping) GetKeyCost(char rune) (int, error) {
+	state := t.newState()
+
+	cost, err := t.press(&state, char)
+	if err != nil {
+		return 0, err
+	}
+
+	return t.round(cost), nil
+}
+
+func (t *TouchTyping) round(cost float64) int {
+	return int(math.Round(cost * t.kbd.resolution))
+}
@@ -0,0 +1,123 @@
+package keyboard
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_TouchTyping(t *testing.T) {
+	t.Parallel()
+
+	kbd, err := NewQWERTY()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	touch, err := NewTouchTyping(kbd, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testData := []struct {
+		Seq      string
+		Expected int
+	}{
+		{"asdf", 0},       // Home row only
+		{"jkl", 0},        // Home row only
+		{"fj", 0},         // Different hands
+		{"ff", 0},         // Same key, no penalty
+		{"fr", 3},         // Left index: f -> r + penalty
+		{"tg", 2 + 1 + 2}, // Left index: f -> t, t -> g + penalty
+		{"qp", 1 + 1},     // Left pinky a -> q, right pinky (9, 2) -> p
+		{"ju", 1 + 2},     // Right index: j -> u + penalty
+		{"aA", 0 + 2 + 0}, // Right pinky (9, 2) -> right Shift (10, 3), the left pinky stays on 'a'
+		{"hello", 1 + 1 + 0 + 0 + 1 + 2},
+	}
+
+	for _, testCase := range testData {
+		dist, err := touch.GetSequenceDistance(testCase.Seq)
+		if err != nil {
+			t.Error(err)
+		}
+
+		if dist != testCase.Expected {
+			t.Errorf("Expected cost of '%s': %d; got: %d", testCase.Seq, testCase.Expected, dist)
+		}
+	}
+}
+
+func Test_TouchTypingStaggered(t *testing.T) {
+	t.Parallel()
+
+	def, err := Lookup("qwerty")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kbd, err := NewFromDefinition(def.Staggered(StaggerANSI()...), WithResolution(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	touch, err := NewTouchTyping(kbd, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testData := []struct {
+		Seq      string
+		Expected int
+	}{
+		{"asdf", 0}, // The homes are on the staggered home row keys
+		{"fj", 0},
+		{"ju", 125 + 200}, // Right index: j (6.75, 2) -> u (6.5, 1) + penalty
+	}
+
+	for _, testCase := range testData {
+		dist, err := touch.GetSequenceDistance(testCase.Seq)
+		if err != nil {
+			t.Error(err)
+		}
+
+		if dist != testCase.Expected {
+			t.Errorf("Expected cost of '%s': %d; got: %d", testCase.Seq, testCase.Expected, dist)
+		}
+	}
+}
+
+func Test_TouchTypingPair(t *testing.T) {
+	t.Parallel()
+
+	kbd, err := NewQWERTY()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	touch, err := NewTouchTyping(kbd, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 'g' after 't': the left index finger travels from 't' and pays the penalty
+	dist, err := touch.GetDistance('t', 'g')
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := 1 + 2; dist != expected {
+		t.Errorf("Expected: %d, got: %d", expected, dist)
+	}
+
+	if _, err := touch.GetDistance('t', 'й'); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Expected error '%v', got '%v'", ErrUnknownKey, err)
+	}
+
+	plain, err := New(QWERTY())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewTouchTyping(plain, 0); !errors.Is(err, ErrNoFingers) {
+		t.Errorf("Expected error '%v', got '%v'", ErrNoFingers, err)
+	}
+}
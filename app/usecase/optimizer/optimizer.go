@@ -0,0 +1,390 @@
+package optimizer
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"strings"
+
+	pkgerr "github.com/pkg/errors"
+	"morphbits.io/app/usecase/app"
+	"morphbits.io/app/usecase/keyboard"
+)
+
+var (
+	ErrEmptyCorpus   = errors.New("no corpus words are typed on the layout")
+	ErrBadIterations = errors.New("iterations must be positive")
+)
+
+const (
+	temperatureSamples = 100
+	coolingRatio       = 1e-3 // Final temperature relative to the initial one
+)
+
+// Corpus is the words to type with their frequencies.
+type Corpus struct {
+	words map[string]int
+}
+
+func NewCorpus() *Corpus {
+	return &Corpus{
+		words: make(map[string]int),
+	}
+}
+
+// Add counts one more occurrence of the word.
+func (c *Corpus) Add(word string) {
+	c.words[word]++
+}
+
+type Config struct {
+	Iterations  int
+	Temperature float64 // Initial annealing temperature in cost units, estimated if zero
+	Seed        int64
+	Pinned      string // Characters which keep their keys
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Iterations:  200000,
+		Temperature: 0,
+		Seed:        1,
+		Pinned:      "",
+	}
+}
+
+type Result struct {
+	Definition *keyboard.Definition
+	Cost       int // Travel to type the corpus on the optimized layout
+	BaseCost   int // Travel to type the corpus on the base layout
+}
+
+// Layout returns the optimized key rows.
+func (r *Result) Layout() keyboard.Layout {
+	return r.Definition.Layout()
+}
+
+// Improvement returns the travel saved in percents of the base layout travel.
+func (r *Result) Improvement() float64 {
+	if r.BaseCost == 0 {
+		return 0
+	}
+
+	return 100 * float64(r.BaseCost-r.Cost) / float64(r.BaseCost)
+}
+
+// Optimize searches for the arrangement of the base layout keys which minimizes the
+// one-finger travel over the corpus with simulated annealing. The key positions and
+// fingers stay where they are, only the characters move. The Shift and AltGr layers
+// can't follow the moved keys, so they are dropped. The words with characters missing
+// on the layout are ignored.
+func Optimize(base *keyboard.Definition, corpus *Corpus, config Config, opts ...keyboard.Option) (*Result, error) {
+	if config.Iterations <= 0 {
+		return nil, pkgerr.Wrapf(ErrBadIterations, "%d", config.Iterations)
+	}
+
+	def := baseLayer(base)
+
+	kbd, err := keyboard.NewFromDefinition(def, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	baseCost, err := corpusCost(kbd, corpus)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := newProblem(def, kbd, corpus, config.Pinned)
+	if err != nil {
+		return nil, err
+	}
+
+	p.anneal(config, rand.New(rand.NewSource(config.Seed))) //nolint:gosec // reproducible search, not secrets
+
+	best := p.definition(def)
+
+	kbd, err = keyboard.NewFromDefinition(best, opts...)
+	if err != nil {
+		return nil, pkgerr.Wrap(err, "optimized layout is broken")
+	}
+
+	cost, err := corpusCost(kbd, corpus)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		Definition: best,
+		Cost:       cost,
+		BaseCost:   baseCost,
+	}, nil
+}
+
+// problem is the quadratic assignment of the characters to the key slots.
+type problem struct {
+	chars   []rune
+	dist    []int // slots x slots distances
+	weights []int // chars x chars counts of the character pairs
+	slot    []int // Current slot of every character
+	movable []int // Characters which may be swapped
+
+	best     []int
+	bestCost int
+}
+
+func newProblem(def *keyboard.Definition, kbd *keyboard.Keyboard, corpus *Corpus, pinned string) (*problem, error) {
+	var chars []rune
+	for _, row := range def.Rows {
+		chars = append(chars, []rune(row.Keys)...)
+	}
+
+	index := make(map[rune]int, len(chars))
+	for i, char := range chars {
+		index[char] = i
+	}
+
+	p := &problem{
+		chars:   chars,
+		dist:    make([]int, len(chars)*len(chars)),
+		weights: make([]int, len(chars)*len(chars)),
+		slot:    make([]int, len(chars)),
+		movable: nil,
+
+		best:     nil,
+		bestCost: 0,
+	}
+
+	// Slot i initially holds character i, so the character distances are the slot distances
+	for i, a := range chars {
+		p.slot[i] = i
+
+		for j, b := range chars {
+			dist, err := kbd.GetDistance(a, b)
+			if err != nil {
+				return nil, pkgerr.Wrapf(err, "failed measure distance from '%c' to '%c'", a, b)
+			}
+
+			p.dist[i*len(chars)+j] = dist
+		}
+
+		if !strings.ContainsRune(pinned, a) {
+			p.movable = append(p.movable, i)
+		}
+	}
+
+	typed := 0
+
+	for word, count := range corpus.words {
+		runes := []rune(word)
+		if !allMapped(runes, index) {
+			continue
+		}
+
+		typed++
+
+		for i := 1; i < len(runes); i++ {
+			p.weights[index[runes[i-1]]*len(chars)+index[runes[i]]] += count
+		}
+	}
+
+	if typed == 0 {
+		return nil, ErrEmptyCorpus
+	}
+
+	p.best = append([]int(nil), p.slot...)
+	p.bestCost = p.cost()
+
+	return p, nil
+}
+
+func (p *problem) anneal(config Config, rnd *rand.Rand) {
+	if len(p.movable) < 2 {
+		return
+	}
+
+	temperature := config.Temperature
+	if temperature <= 0 {
+		temperature = p.estimateTemperature(rnd)
+	}
+
+	cooling := math.Pow(coolingRatio, 1/float64(config.Iterations))
+	cost := p.bestCost
+
+	for i := 0; i < config.Iterations; i++ {
+		a, b := p.pick(rnd)
+		delta := p.swap(a, b)
+
+		if delta > 0 && (temperature <= 0 || rnd.Float64() >= math.Exp(-float64(delta)/temperature)) {
+			p.swap(a, b)
+		} else {
+			cost += delta
+
+			if cost < p.bestCost {
+				p.bestCost = cost
+				copy(p.best, p.slot)
+			}
+		}
+
+		temperature *= cooling
+	}
+}
+
+// estimateTemperature makes the typical worsening swap accepted with the probability 1/e.
+func (p *problem) estimateTemperature(rnd *rand.Rand) float64 {
+	total := 0
+
+	for i := 0; i < temperatureSamples; i++ {
+		a, b := p.pick(rnd)
+		delta := p.swap(a, b)
+		p.swap(a, b)
+
+		if delta < 0 {
+			delta = -delta
+		}
+
+		total += delta
+	}
+
+	return float64(total) / temperatureSamples
+}
+
+func (p *problem) pick(rnd *rand.Rand) (int, int) {
+	a := rnd.Intn(len(p.movable))
+	b := rnd.Intn(len(p.movable) - 1)
+
+	if b >= a {
+		b++
+	}
+
+	return p.movable[a], p.movable[b]
+}
+
+// swap exchanges the slots of two characters and returns the change of the cost.
+func (p *problem) swap(a, b int) int {
+	before := p.involved(a, b)
+	p.slot[a], p.slot[b] = p.slot[b], p.slot[a]
+
+	return p.involved(a, b) - before
+}
+
+// involved sums the costs of all the pairs with any of two characters.
+func (p *problem) involved(a, b int) int {
+	n := len(p.chars)
+	sum := 0
+
+	for c := 0; c < n; c++ {
+		sum += p.weights[a*n+c]*p.dist[p.slot[a]*n+p.slot[c]] + p.weights[b*n+c]*p.dist[p.slot[b]*n+p.slot[c]]
+
+		if c != a && c != b {
+			sum += p.weights[c*n+a]*p.dist[p.slot[c]*n+p.slot[a]] + p.weights[c*n+b]*p.dist[p.slot[c]*n+p.slot[b]]
+		}
+	}
+
+	return sum
+}
+
+func (p *problem) cost() int {
+	n := len(p.chars)
+	sum := 0
+
+	for a := 0; a < n; a++ {
+		for b := 0; b < n; b++ {
+			sum += p.weights[a*n+b] * p.dist[p.slot[a]*n+p.slot[b]]
+		}
+	}
+
+	return sum
+}
+
+// definition puts the characters into the slots of the best arrangement.
+func (p *problem) definition(def *keyboard.Definition) *keyboard.Definition {
+	placed := make([]rune, len(p.chars))
+	for char, slot := range p.best {
+		placed[slot] = p.chars[char]
+	}
+
+	result := def.Clone()
+
+	for i := range result.Rows {
+		keys := len([]rune(result.Rows[i].Keys))
+		result.Rows[i].Keys = string(placed[:keys])
+		placed = placed[keys:]
+	}
+
+	if result.Effort != nil && result.Effort.Keys != nil {
+		result.Effort.Keys = p.effortKeys(result.Effort.Keys)
+	}
+
+	return result
+}
+
+// effortKeys renames the key costs after the characters now on the keys, the costs stay with the keys.
+func (p *problem) effortKeys(costs map[string]float64) map[string]float64 {
+	moved := make(map[rune]rune, len(p.chars))
+	for char, slot := range p.best {
+		moved[p.chars[slot]] = p.chars[char]
+	}
+
+	keys := make(map[string]float64, len(costs))
+
+	for name, cost := range costs {
+		if char, err := keyboard.ParseKey(name); err == nil {
+			if to, ok := moved[char]; ok {
+				name = string(to)
+			}
+		}
+
+		keys[name] += cost
+	}
+
+	return keys
+}
+
+// baseLayer copies the definition without the modifier layers.
+func baseLayer(def *keyboard.Definition) *keyboard.Definition {
+	clone := def.Clone()
+	clone.Modifiers = nil
+
+	for i := range clone.Rows {
+		clone.Rows[i].Shift = ""
+		clone.Rows[i].AltGr = ""
+	}
+
+	return clone
+}
+
+// corpusCost sums the travel inside every typed word the same way the passphrase search does.
+func corpusCost(kbd *keyboard.Keyboard, corpus *Corpus) (int, error) {
+	total := 0
+
+	for word, count := range corpus.words {
+		mapped := true
+		for _, char := range word {
+			mapped = mapped && kbd.IsMapped(char)
+		}
+
+		if !mapped {
+			continue
+		}
+
+		cost, err := app.WordCost(word, kbd)
+		if err != nil {
+			return 0, err
+		}
+
+		total += count * cost
+	}
+
+	return total, nil
+}
+
+func allMapped(runes []rune, index map[rune]int) bool {
+	for _, char := range runes {
+		if _, ok := index[char]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
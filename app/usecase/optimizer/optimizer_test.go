@@ -0,0 +1,143 @@
+package optimizer
+
+import (
+	"errors"
+	"testing"
+
+	"morphbits.io/app/usecase/keyboard"
+)
+
+func Test_OptimizeRow(t *testing.T) {
+	t.Parallel()
+
+	corpus := NewCorpus()
+	corpus.Add("ab")
+	corpus.Add("ba")
+	corpus.Add("cd")
+
+	base := keyboard.NewDefinition("row", keyboard.Layout{"axcybzd"})
+
+	config := DefaultConfig()
+	config.Iterations = 1000
+
+	result, err := Optimize(base, corpus, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.BaseCost != 12 || result.Cost != 3 {
+		t.Errorf("Expected costs 12 -> 3; got: %d -> %d, layout %v", result.BaseCost, result.Cost, result.Layout())
+	}
+
+	if len(result.Layout()) != 1 || len([]rune(result.Layout()[0])) != 7 {
+		t.Errorf("Expected the layout of the same shape; got: %v", result.Layout())
+	}
+}
+
+func Test_OptimizePinned(t *testing.T) {
+	t.Parallel()
+
+	corpus := NewCorpus()
+	corpus.Add("ab")
+
+	base := keyboard.NewDefinition("row", keyboard.Layout{"axyb"})
+
+	config := DefaultConfig()
+	config.Iterations = 1000
+	config.Pinned = "ab"
+
+	result, err := Optimize(base, corpus, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Cost != 3 || result.Layout()[0][0] != 'a' || result.Layout()[0][3] != 'b' {
+		t.Errorf("Expected pinned keys; got: %v, cost %d", result.Layout(), result.Cost)
+	}
+}
+
+func Test_OptimizeEffort(t *testing.T) {
+	t.Parallel()
+
+	corpus := NewCorpus()
+	corpus.Add("ab")
+
+	// The last key is hard to press, 'b' moves off it
+	base := keyboard.NewDefinition("row", keyboard.Layout{"axb"})
+	base.Effort = &keyboard.Effort{
+		Press:   0,
+		Keys:    map[string]float64{"b": 10},
+		Rows:    nil,
+		Fingers: nil,
+		Reach:   0,
+		Repeat:  0,
+	}
+
+	config := DefaultConfig()
+	config.Iterations = 1000
+
+	result, err := Optimize(base, corpus, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	layout := []rune(result.Layout()[0])
+
+	if result.BaseCost != 12 || result.Cost != 1 || layout[2] == 'b' {
+		t.Errorf("Expected costs 12 -> 1; got: %d -> %d, layout %v", result.BaseCost, result.Cost, result.Layout())
+	}
+
+	if keys := result.Definition.Effort.Keys; len(keys) != 1 || keys[string(layout[2])] != 10 {
+		t.Errorf("Expected the key cost on the last key '%c'; got: %v", layout[2], keys)
+	}
+}
+
+func Test_OptimizeQWERTY(t *testing.T) {
+	t.Parallel()
+
+	base, err := keyboard.Lookup("qwerty")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	corpus := NewCorpus()
+	for _, word := range []string{"password", "keyboard", "correct", "horse", "battery", "staple", "Ж"} {
+		corpus.Add(word)
+	}
+
+	config := DefaultConfig()
+	config.Iterations = 20000
+
+	result, err := Optimize(base, corpus, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Cost >= result.BaseCost || result.Improvement() <= 0 {
+		t.Errorf("Expected improvement over %d; got: %d", result.BaseCost, result.Cost)
+	}
+
+	if _, err := keyboard.NewFromDefinition(result.Definition); err != nil {
+		t.Errorf("Expected valid layout; got: %v", err)
+	}
+}
+
+func Test_OptimizeErrors(t *testing.T) {
+	t.Parallel()
+
+	corpus := NewCorpus()
+	corpus.Add("ж")
+
+	base := keyboard.NewDefinition("row", keyboard.Layout{"ab"})
+
+	if _, err := Optimize(base, corpus, DefaultConfig()); !errors.Is(err, ErrEmptyCorpus) {
+		t.Errorf("Expected empty corpus error; got: %v", err)
+	}
+
+	config := DefaultConfig()
+	config.Iterations = 0
+
+	if _, err := Optimize(base, corpus, config); !errors.Is(err, ErrBadIterations) {
+		t.Errorf("Expected bad iterations error; got: %v", err)
+	}
+}
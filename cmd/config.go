@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	pkgerr "github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const configFlag = "config"
+
+var errConfigSyntax = errors.New("bad config file line")
+
+// loadConfigFile sets the flags which are not given on the command line from the file.
+// Every line is "flag = value" with the flag name without the dash, e.g. "words = 5",
+// the empty lines and the lines starting with # are skipped.
+func loadConfigFile(flags *flag.FlagSet, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return pkgerr.Wrapf(err, "failed open config file '%s'", path)
+	}
+
+	defer f.Close()
+
+	explicit := make(map[string]bool)
+	flags.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+
+	scanner := bufio.NewScanner(f)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		name, value, ok := strings.Cut(text, "=")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+
+		if !ok || name == "" || name == configFlag {
+			return pkgerr.Wrapf(errConfigSyntax, "'%s' line %d", path, line)
+		}
+
+		if explicit[name] {
+			continue
+		}
+
+		if err := flags.Set(name, value); err != nil {
+			return pkgerr.Wrapf(err, "'%s' line %d", path, line)
+		}
+	}
+
+	return pkgerr.Wrapf(scanner.Err(), "failed read config file '%s'", path)
+}
+
+func envInt(name string, fallback int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.WithField("err", err).Fatalf("Bad %s", name)
+	}
+
+	return n
+}
+
+func envBool(name string, fallback bool) bool {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.WithField("err", err).Fatalf("Bad %s", name)
+	}
+
+	return b
+}
+
+func envFloat(name string, fallback float64) float64 {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		log.WithField("err", err).Fatalf("Bad %s", name)
+	}
+
+	return f
+}
+
+func envDuration(name string, fallback time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.WithField("err", err).Fatalf("Bad %s", name)
+	}
+
+	return d
+}
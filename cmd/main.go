@@ -1,18 +1,118 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"os"
+	"os/signal"
 	"runtime/pprof"
+	"strings"
+	"syscall"
 	"time"
 
+	pkgerr "github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"morphbits.io/app/interface/dictionary"
+	"morphbits.io/app/interface/importer"
 	"morphbits.io/app/interface/metrics"
+	"morphbits.io/app/interface/render"
 	"morphbits.io/app/usecase/app"
 	"morphbits.io/app/usecase/keyboard"
 )
 
 func main() {
+	var kbdFlags keyboardFlags
+
+	config := app.DefaultConfig()
+
+	flag.StringVar(&kbdFlags.file, "layout", os.Getenv("LAYOUT"),
+		"path to the JSON keyboard layout definition, overrides -keyboard, not for the mobile model (env LAYOUT)")
+	flag.StringVar(&kbdFlags.format, "layout-format", envOr("LAYOUT_FORMAT", layoutJSON),
+		"format of the -layout file: json, xkb (X11 symbols file, path(variant) selects the variant) "+
+			"or kle (keyboard-layout-editor JSON) (env LAYOUT_FORMAT)")
+	flag.StringVar(&kbdFlags.name, "keyboard", envOr("KEYBOARD", defaultKeyboard),
+		"name of the built-in keyboard layout: "+strings.Join(keyboard.Names(), ", ")+
+			", the mobile model has its own phone qwerty (env KEYBOARD)")
+	flag.StringVar(&kbdFlags.metric, "metric", envOr("METRIC", keyboard.Manhattan.String()),
+		"distance metric: manhattan, euclidean, chebyshev or hops (env METRIC)")
+	flag.BoolVar(&kbdFlags.stagger, "stagger", false, "apply the ANSI row stagger to the built-in layout")
+	flag.IntVar(&kbdFlags.resolution, "resolution", 1, "distance units per key width")
+	flag.StringVar(&kbdFlags.model, "model", envOr("MODEL", modelOneFinger),
+		"cost model: one-finger, touch (multi-finger touch typing), mobile (two-thumb phone keyboard) "+
+			"or fitts (one-finger movement time in milliseconds) (env MODEL)")
+	flag.Float64Var(&kbdFlags.sameFingerPenalty, "same-finger-penalty", 1,
+		"touch model penalty in key widths for two different keys in a row typed by the same finger")
+	flag.Float64Var(&kbdFlags.fittsA, "fitts-a", keyboard.DefaultFittsA,
+		"fitts model time of a keystroke in milliseconds")
+	flag.Float64Var(&kbdFlags.fittsB, "fitts-b", keyboard.DefaultFittsB,
+		"fitts model time per bit of the movement difficulty in milliseconds")
+	flag.BoolVar(&kbdFlags.precompute, "precompute", false,
+		"precompute the one-finger distances into a matrix, PINs are not checked for lines and no route is drawn then")
+	flag.StringVar(&kbdFlags.matrix, "matrix", os.Getenv("MATRIX"),
+		"path to the CSV distance matrix to use instead of the keyboard (env MATRIX)")
+	flag.StringVar(&kbdFlags.exportMatrix, "export-matrix", "",
+		"path to save the CSV distance matrix of the one-finger keyboard")
+
+	unknownKeys := flag.String("unknown", envOr("UNKNOWN_KEYS", app.SkipUnknown.String()),
+		"policy for the words with characters missing on the layout: skip, fail or transliterate (env UNKNOWN_KEYS)")
+	startKey := flag.String("start", os.Getenv("START_KEY"),
+		"key the finger rests on before typing, e.g. g (env START_KEY)")
+	endKey := flag.String("end", os.Getenv("END_KEY"),
+		"key pressed after the passphrase: enter, tab or a character (env END_KEY)")
+	separator := flag.String("separator", os.Getenv("SEPARATOR"),
+		"typed between the words, counted in the length: none, space, hyphen, digit (the cheapest one), "+
+			"a special key, e.g. tab, or any string (env SEPARATOR)")
+	renderFormat := flag.String("render", os.Getenv("RENDER"),
+		"draw the route of the best passphrase over the keyboard: ascii or svg (env RENDER)")
+	renderOut := flag.String("render-out", "", "path to save the drawn route, stdout by default")
+	mode := flag.String("mode", envOr("MODE", app.PassphraseMode.String()),
+		"what to generate: passphrase or pin (env MODE)")
+	flag.IntVar(&config.Pass.Words, "words", envInt("WORDS", config.Pass.Words),
+		"number of words in the passphrase, 2 to 8 (env WORDS)")
+	flag.IntVar(&config.Pass.MinLength, "min-length", envInt("MIN_LENGTH", config.Pass.MinLength),
+		"minimum number of characters in the passphrase, the separators included (env MIN_LENGTH)")
+	flag.IntVar(&config.Pass.MaxLength, "max-length", envInt("MAX_LENGTH", config.Pass.MaxLength),
+		"maximum number of characters in the passphrase (env MAX_LENGTH)")
+	flag.BoolVar(&config.Pass.Unique, "unique", envBool("UNIQUE_WORDS", config.Pass.Unique),
+		"use every word once in the passphrase (env UNIQUE_WORDS)")
+	flag.IntVar(&config.Pass.Results, "results", envInt("RESULTS", config.Pass.Results),
+		"number of the cheapest passphrases to show, ranked (env RESULTS)")
+	flag.BoolVar(&config.Pass.Random, "random", envBool("RANDOM", config.Pass.Random),
+		"pick one passphrase with crypto/rand among the ones within the budget of the cheapest one (env RANDOM)")
+	flag.IntVar(&config.Pass.Budget, "budget", envInt("BUDGET", config.Pass.Budget),
+		"extra cost over the cheapest passphrase allowed for the random pick (env BUDGET)")
+	flag.IntVar(&config.Compose.Upper, "upper", envInt("UPPER", config.Compose.Upper),
+		"minimum number of capital letters in the passphrase (env UPPER)")
+	flag.IntVar(&config.Compose.Digits, "digits", envInt("DIGITS", config.Compose.Digits),
+		"minimum number of digits in the passphrase (env DIGITS)")
+	flag.IntVar(&config.Compose.Symbols, "symbols", envInt("SYMBOLS", config.Compose.Symbols),
+		"minimum number of symbols in the passphrase (env SYMBOLS)")
+	flag.StringVar(&config.Compose.Charset, "symbol-set", envOr("SYMBOL_SET", config.Compose.Charset),
+		"characters counted as the symbols (env SYMBOL_SET)")
+	flag.Float64Var(&config.Strength.MinBits, "min-bits", envFloat("MIN_BITS", config.Strength.MinBits),
+		"target selection entropy in bits, the weaker result is warned about, 0 for no check (env MIN_BITS)")
+	flag.BoolVar(&config.Strength.Fail, "fail-weak", envBool("FAIL_WEAK", config.Strength.Fail),
+		"fail instead of the warning when the result is below -min-bits (env FAIL_WEAK)")
+	flag.IntVar(&config.PIN.Length, "pin-length", envInt("PIN_LENGTH", config.PIN.Length),
+		"number of PIN digits (env PIN_LENGTH)")
+	flag.IntVar(&config.PIN.MinDistinct, "pin-min-distinct", envInt("PIN_MIN_DISTINCT", config.PIN.MinDistinct),
+		"minimum number of different PIN digits (env PIN_MIN_DISTINCT)")
+	flag.BoolVar(&config.PIN.AllowRepeats, "pin-allow-repeats",
+		envBool("PIN_ALLOW_REPEATS", config.PIN.AllowRepeats),
+		"allow the same PIN digit twice in a row (env PIN_ALLOW_REPEATS)")
+	flag.BoolVar(&config.PIN.AllowSequences, "pin-allow-sequences",
+		envBool("PIN_ALLOW_SEQUENCES", config.PIN.AllowSequences),
+		"allow PINs with the constant step, e.g. 1234 (env PIN_ALLOW_SEQUENCES)")
+	flag.BoolVar(&config.PIN.AllowLines, "pin-allow-lines", envBool("PIN_ALLOW_LINES", config.PIN.AllowLines),
+		"allow PINs with all the keys on a straight line, e.g. 2580 (env PIN_ALLOW_LINES)")
+	timeout := flag.Duration("timeout", envDuration("TIMEOUT", 0),
+		"stop the search after the time, e.g. 30s, and show the best found so far, 0 for no limit (env TIMEOUT)")
+	configFile := flag.String(configFlag, os.Getenv("CONFIG"),
+		"path to the config file with \"flag = value\" lines, the command line overrides it (env CONFIG)")
+	flag.Parse()
+
 	start := time.Now()
 	////////////////////////////////////
 	//// go tool pprof main main.prof
@@ -33,9 +133,16 @@ func main() {
 		FullTimestamp:   true,
 	})
 
+	if *configFile != "" {
+		if err := loadConfigFile(flag.CommandLine, *configFile); err != nil {
+			log.WithField("err", err).Info("Bad configuration")
+			return
+		}
+	}
+
 	m := metrics.New()
 
-	kbd, err := keyboard.NewQWERTY()
+	calc, err := newCalculator(&kbdFlags)
 	if err != nil {
 		log.WithField("err", err).Info("Failed init keyboard")
 		return
@@ -48,13 +155,345 @@ func main() {
 
 	dictReader := dictionary.NewFileReader(englishWords)
 
-	application := app.New(m, dictReader, kbd)
+	config.UnknownKeys, err = app.ParseUnknownKeyPolicy(*unknownKeys)
+	if err != nil {
+		log.WithField("err", err).Info("Bad configuration")
+		return
+	}
+
+	config.Mode, err = app.ParseMode(*mode)
+	if err != nil {
+		log.WithField("err", err).Info("Bad configuration")
+		return
+	}
+
+	for _, key := range []struct {
+		name   string
+		target *rune
+	}{
+		{*startKey, &config.Terminals.Start},
+		{*endKey, &config.Terminals.End},
+	} {
+		if key.name == "" {
+			continue
+		}
+
+		if *key.target, err = keyboard.ParseKey(key.name); err != nil {
+			log.WithField("err", err).Info("Bad configuration")
+			return
+		}
+	}
+
+	if *separator != "" {
+		if config.Terminals.Separator, err = parseSeparator(*separator); err != nil {
+			log.WithField("err", err).Info("Bad configuration")
+			return
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		log.WithField("err", err).Info("Bad configuration")
+		return
+	}
+
+	application := app.New(m, dictReader, calc, config)
+
+	// Ctrl-C stops the search with the best found so far, the second one kills the process
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
 
-	if err := application.Run(); err != nil {
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	result, err := application.Run(ctx)
+	if err != nil {
 		log.WithField("err", err).Info("Application terminated with error code")
 		return
 	}
 
+	if result.Partial {
+		log.WithField("err", ctx.Err()).Warn("Search interrupted, the results are the best found so far")
+	}
+
+	printResult(result)
+	printEntropy(result)
+
+	if *renderFormat != "" {
+		err := renderBest(&kbdFlags, calc, &result.Config.Terminals, result.Passes, *renderFormat, *renderOut)
+		if err != nil {
+			log.WithField("err", err).Info("Failed draw route")
+		}
+	}
+
 	log.WithField("elapsed", time.Since(start)).Info("Done")
 	log.WithFields(m.GetMetrics()).Info("Metrics")
 }
+
+const (
+	layoutJSON = "json"
+	layoutXKB  = "xkb"
+	layoutKLE  = "kle"
+)
+
+const defaultKeyboard = "qwerty"
+
+const (
+	modelOneFinger = "one-finger"
+	modelTouch     = "touch"
+	modelMobile    = "mobile"
+	modelFitts     = "fitts"
+)
+
+var (
+	errUnknownModel  = errors.New("unknown cost model")
+	errUnknownFormat = errors.New("unknown layout format")
+	errUnknownRender = errors.New("unknown route format")
+	errNoPass        = errors.New("nothing to draw")
+	errNoKeys        = errors.New("no key positions to draw the route")
+	errMobileLayout  = errors.New("the mobile model has its own phone qwerty layout")
+)
+
+type keyboardFlags struct {
+	name, file string
+	format     string
+	metric     string
+	stagger    bool
+	resolution int
+
+	model             string
+	sameFingerPenalty float64
+	fittsA, fittsB    float64
+
+	precompute           bool
+	matrix, exportMatrix string
+}
+
+func newCalculator(flags *keyboardFlags) (app.DistanceCalculator, error) {
+	if flags.matrix != "" {
+		return keyboard.LoadMatrix(flags.matrix)
+	}
+
+	if flags.model == modelMobile {
+		if flags.file != "" || flags.name != defaultKeyboard {
+			return nil, pkgerr.Wrapf(errMobileLayout, "-keyboard '%s', -layout '%s'", flags.name, flags.file)
+		}
+
+		return keyboard.NewMobile(keyboard.PhoneQWERTY(), flags.resolution)
+	}
+
+	kbd, err := newKeyboard(flags)
+	if err != nil {
+		return nil, err
+	}
+
+	switch flags.model {
+	case modelOneFinger:
+		return precompute(kbd, flags)
+	case modelTouch:
+		return keyboard.NewTouchTyping(kbd, flags.sameFingerPenalty)
+	case modelFitts:
+		return keyboard.NewFitts(kbd, flags.fittsA, flags.fittsB)
+	}
+
+	return nil, pkgerr.Wrapf(errUnknownModel, "'%s'", flags.model)
+}
+
+func precompute(kbd *keyboard.Keyboard, flags *keyboardFlags) (app.DistanceCalculator, error) {
+	if !flags.precompute && flags.exportMatrix == "" {
+		return kbd, nil
+	}
+
+	matrix, err := keyboard.NewKeyboardMatrix(kbd)
+	if err != nil {
+		return nil, err
+	}
+
+	if flags.exportMatrix != "" {
+		if err := keyboard.SaveMatrix(flags.exportMatrix, matrix); err != nil {
+			return nil, err
+		}
+	}
+
+	if flags.precompute {
+		return matrix, nil
+	}
+
+	return kbd, nil
+}
+
+func newKeyboard(flags *keyboardFlags) (*keyboard.Keyboard, error) {
+	metric, err := keyboard.ParseMetric(flags.metric)
+	if err != nil {
+		return nil, err
+	}
+
+	def, err := newDefinition(flags)
+	if err != nil {
+		return nil, err
+	}
+
+	return keyboard.NewFromDefinition(def, keyboard.WithMetric(metric), keyboard.WithResolution(flags.resolution))
+}
+
+func newDefinition(flags *keyboardFlags) (*keyboard.Definition, error) {
+	var (
+		def *keyboard.Definition
+		err error
+	)
+
+	switch {
+	case flags.file == "":
+		def, err = keyboard.Lookup(flags.name)
+	case flags.format == layoutJSON:
+		def, err = keyboard.LoadLayout(flags.file)
+	case flags.format == layoutXKB:
+		def, err = importer.LoadXKB(flags.file)
+	case flags.format == layoutKLE:
+		def, err = importer.LoadKLE(flags.file)
+	default:
+		err = pkgerr.Wrapf(errUnknownFormat, "'%s'", flags.format)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if flags.stagger {
+		def = def.Staggered(keyboard.StaggerANSI()...)
+	}
+
+	return def, nil
+}
+
+// parseSeparator accepts the special key names as well, e.g. tab.
+func parseSeparator(name string) (app.Separator, error) {
+	separator, err := app.ParseSeparator(name)
+	if err != nil || separator.Kind != app.CustomSeparator {
+		return separator, err
+	}
+
+	if char, err := keyboard.ParseKey(name); err == nil {
+		separator.Text = string(char)
+	}
+
+	return separator, nil
+}
+
+func printResult(result *app.Result) {
+	for i, pass := range result.Passes {
+		if result.Config.Mode == app.PINMode {
+			log.WithFields(log.Fields{
+				"pin":  pass.Text,
+				"dist": pass.Cost,
+			}).Info("Best PIN")
+
+			continue
+		}
+
+		fields := log.Fields{
+			"rank":   i + 1,
+			"pass":   pass.Text,
+			"dist":   pass.Cost,
+			"words":  pass.WordCosts,
+			"joins":  pass.Boundaries,
+			"length": pass.Length,
+			"layout": result.Layout,
+		}
+
+		if pass.Extra != 0 {
+			fields["base"] = pass.Base
+			fields["extra"] = pass.Extra
+		}
+
+		if result.Config.Pass.Random {
+			fields["pool"] = result.Pool
+			log.WithFields(fields).Info("Random pass")
+
+			continue
+		}
+
+		log.WithFields(fields).Info("Best pass")
+	}
+}
+
+func printEntropy(result *app.Result) {
+	fields := log.Fields{
+		"selection":  fmt.Sprintf("%.1f", result.Entropy.Selection),
+		"dictionary": fmt.Sprintf("%.1f", result.Entropy.Dictionary),
+	}
+
+	if result.Entropy.Weak {
+		fields["target"] = result.Config.Strength.MinBits
+		log.WithFields(fields).Warn("Weak result: selection entropy is below the target")
+
+		return
+	}
+
+	log.WithFields(fields).Info("Entropy bits")
+}
+
+// renderBest draws the route of the best passphrase over the keyboard. The keys are placed
+// and the steps are measured by the active cost model, so it must know the key positions.
+func renderBest(
+	flags *keyboardFlags, calc app.DistanceCalculator, terminals *app.Terminals, best []app.Pass, format, path string,
+) error {
+	if len(best) == 0 {
+		return errNoPass
+	}
+
+	locator, ok := calc.(app.KeyLocator)
+	if !ok {
+		return pkgerr.Wrapf(errNoKeys, "cost model %T", calc)
+	}
+
+	def, err := newDefinition(flags)
+	if err != nil {
+		return err
+	}
+
+	costs, err := app.KeystrokeCosts(best[0].Text, terminals, calc)
+	if err != nil {
+		return err
+	}
+
+	route, err := render.NewRoute(def.Layout(), locator, best[0].Text, costs)
+	if err != nil {
+		return err
+	}
+
+	out := os.Stdout
+
+	if path != "" {
+		if out, err = os.Create(path); err != nil {
+			return pkgerr.Wrapf(err, "failed create route file '%s'", path)
+		}
+
+		defer out.Close()
+	}
+
+	switch format {
+	case "ascii":
+		return route.ASCII(out)
+	case "svg":
+		return route.SVG(out)
+	}
+
+	return pkgerr.Wrapf(errUnknownRender, "'%s'", format)
+}
+
+func envOr(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+
+	return fallback
+}
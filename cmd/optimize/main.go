@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"morphbits.io/app/interface/dictionary"
+	"morphbits.io/app/usecase/keyboard"
+	"morphbits.io/app/usecase/optimizer"
+)
+
+func main() {
+	config := optimizer.DefaultConfig()
+
+	dict := flag.String("dict", envOr("DICT", "/etc/morphbits/data/corncob_lowercase.txt"),
+		"path to the words to type, one per line (env DICT)")
+	name := flag.String("keyboard", envOr("KEYBOARD", "qwerty"),
+		"name of the built-in layout to start from: "+strings.Join(keyboard.Names(), ", ")+" (env KEYBOARD)")
+	file := flag.String("layout", os.Getenv("LAYOUT"),
+		"path to the JSON layout definition to start from, overrides -keyboard (env LAYOUT)")
+	metricName := flag.String("metric", envOr("METRIC", keyboard.Manhattan.String()),
+		"distance metric: manhattan, euclidean, chebyshev or hops (env METRIC)")
+	stagger := flag.Bool("stagger", false, "apply the ANSI row stagger to the built-in layout")
+	resolution := flag.Int("resolution", 1, "distance units per key width")
+	out := flag.String("out", "optimized.json", "path to save the optimized layout definition")
+	flag.IntVar(&config.Iterations, "iterations", config.Iterations, "number of the annealing steps")
+	flag.Int64Var(&config.Seed, "seed", config.Seed, "random seed of the search")
+	flag.StringVar(&config.Pinned, "pinned", config.Pinned, "characters which keep their keys")
+	flag.Parse()
+
+	log.SetFormatter(&log.TextFormatter{ //nolint:exhaustruct // other fields are defaults
+		TimestampFormat: time.RFC3339,
+		FullTimestamp:   true,
+	})
+
+	start := time.Now()
+
+	metric, err := keyboard.ParseMetric(*metricName)
+	if err != nil {
+		log.WithField("err", err).Info("Bad configuration")
+		return
+	}
+
+	var base *keyboard.Definition
+
+	if *file == "" {
+		base, err = keyboard.Lookup(*name)
+	} else {
+		base, err = keyboard.LoadLayout(*file)
+	}
+
+	if err != nil {
+		log.WithField("err", err).Info("Failed load layout")
+		return
+	}
+
+	if *stagger {
+		base = base.Staggered(keyboard.StaggerANSI()...)
+	}
+
+	corpus := optimizer.NewCorpus()
+
+	err = dictionary.NewFileReader(*dict).Run(context.Background(), func(word string) error {
+		corpus.Add(strings.ToLower(word))
+		return nil
+	})
+	if err != nil {
+		log.WithField("err", err).Info("Failed read dictionary")
+		return
+	}
+
+	result, err := optimizer.Optimize(base, corpus, config,
+		keyboard.WithMetric(metric), keyboard.WithResolution(*resolution))
+	if err != nil {
+		log.WithField("err", err).Info("Failed optimize layout")
+		return
+	}
+
+	result.Definition.Name = base.Name + "-optimized"
+
+	if err := keyboard.SaveLayout(*out, result.Definition); err != nil {
+		log.WithField("err", err).Info("Failed save layout")
+		return
+	}
+
+	for _, row := range result.Layout() {
+		log.WithField("keys", row).Info("Optimized row")
+	}
+
+	log.WithFields(log.Fields{
+		"base_layout": base.Name,
+		"base_cost":   result.BaseCost,
+		"cost":        result.Cost,
+		"improvement": result.Improvement(),
+		"saved":       *out,
+		"elapsed":     time.Since(start),
+	}).Info("Done")
+}
+
+func envOr(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+
+	return fallback
+}